@@ -0,0 +1,278 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/quail-ink/bizdocgen/core"
+)
+
+// DefaultBatchFilenameTemplate names batch outputs after the document type
+// and ID.
+const DefaultBatchFilenameTemplate = "{{.Type}}-{{.ID}}.pdf"
+
+const (
+	BatchStageLoad     = "load"
+	BatchStageValidate = "validate"
+	BatchStageRender   = "render"
+	BatchStageWrite    = "write"
+)
+
+type (
+	// BatchSource is one params document of a batch.
+	BatchSource struct {
+		// Name identifies the document in reports, e.g. "statements.yaml#2"
+		Name string
+		Data []byte
+		// Err is the error reading or splitting the file of the source,
+		// which is then reported as failing to load without stopping the
+		// other sources.
+		Err error
+	}
+
+	BatchOptions struct {
+		Type   DocumentType
+		Config Config
+		// Workers bounds the number of documents rendered concurrently,
+		// defaulting to the number of CPUs.
+		Workers   int
+		OutputDir string
+		// FilenameTemplate is a text/template executed with a
+		// BatchFilenameData, e.g. "{{.Date.Format "20060102"}}-{{.Party}}.pdf",
+		// defaulting to DefaultBatchFilenameTemplate.
+		FilenameTemplate string
+	}
+
+	BatchFilenameData struct {
+		DocumentInfo
+		// Index of the source in the batch
+		Index int
+	}
+
+	BatchResult struct {
+		Source string
+		Output string
+		// Stage at which the document failed, empty on success
+		Stage string
+		Err   error
+	}
+
+	BatchReport struct {
+		Results []BatchResult
+	}
+)
+
+// Failed returns the results of the documents that could not be generated.
+func (r *BatchReport) Failed() []BatchResult {
+	failed := []BatchResult{}
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// CollectBatchSources expands the given paths into batch sources. A path may
+// be a directory (all *.yaml and *.yml files in it), a glob pattern or a
+// file, and every file may hold a multi-document YAML stream. Files that
+// cannot be read or split become a single source carrying the error.
+func CollectBatchSources(paths ...string) ([]BatchSource, error) {
+	filenames := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			for _, ext := range []string{"*.yaml", "*.yml"} {
+				matches, _ := filepath.Glob(filepath.Join(path, ext))
+				filenames = append(filenames, matches...)
+			}
+		case err == nil:
+			filenames = append(filenames, path)
+		default:
+			matches, globErr := filepath.Glob(path)
+			if globErr != nil {
+				return nil, globErr
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%w: %s", core.ErrFileNotFound, path)
+			}
+			filenames = append(filenames, matches...)
+		}
+	}
+	sort.Strings(filenames)
+
+	sources := []BatchSource{}
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			sources = append(sources, BatchSource{Name: filename, Err: err})
+			continue
+		}
+		docs, err := core.SplitDocuments(data)
+		if err != nil {
+			if perr, ok := err.(*core.ParseError); ok {
+				perr.Filename = filename
+			}
+			sources = append(sources, BatchSource{Name: filename, Err: err})
+			continue
+		}
+		for ix, doc := range docs {
+			name := filename
+			if len(docs) > 1 {
+				name = fmt.Sprintf("%s#%d", filename, ix+1)
+			}
+			sources = append(sources, BatchSource{Name: name, Data: doc})
+		}
+	}
+	return sources, nil
+}
+
+// GenerateBatch renders every source with a bounded pool of workers and
+// writes the documents to opts.OutputDir. Documents failing validation are
+// not rendered. A failing document never aborts the others; its error is
+// recorded in the report instead.
+func GenerateBatch(ctx context.Context, sources []BatchSource, opts BatchOptions) (*BatchReport, error) {
+	if opts.FilenameTemplate == "" {
+		opts.FilenameTemplate = DefaultBatchFilenameTemplate
+	}
+	tmpl, err := template.New("filename").Parse(opts.FilenameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filename template: %w", err)
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if opts.OutputDir != "" {
+		if err := os.MkdirAll(opts.OutputDir, 0777); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &BatchReport{Results: make([]BatchResult, len(sources))}
+	outputs := newOutputNames()
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ix := range jobs {
+				// jobs taken just before the cancellation are not rendered
+				if err := ctx.Err(); err != nil {
+					report.Results[ix] = BatchResult{Source: sources[ix].Name, Stage: BatchStageLoad, Err: err}
+					continue
+				}
+				report.Results[ix] = generateBatchItem(ix, sources[ix], opts, tmpl, outputs)
+			}
+		}()
+	}
+
+	for ix := range sources {
+		// select picks randomly among ready cases, check the context first
+		// so that no job is sent once it is cancelled
+		if err := ctx.Err(); err != nil {
+			report.Results[ix] = BatchResult{Source: sources[ix].Name, Stage: BatchStageLoad, Err: err}
+			continue
+		}
+		select {
+		case jobs <- ix:
+		case <-ctx.Done():
+			report.Results[ix] = BatchResult{Source: sources[ix].Name, Stage: BatchStageLoad, Err: ctx.Err()}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return report, nil
+}
+
+func generateBatchItem(ix int, source BatchSource, opts BatchOptions, tmpl *template.Template, outputs *outputNames) (result BatchResult) {
+	result.Source = source.Name
+	defer func() {
+		if r := recover(); r != nil {
+			result.Stage = BatchStageRender
+			result.Err = fmt.Errorf("panic while rendering: %v", r)
+		}
+	}()
+
+	if source.Err != nil {
+		result.Stage, result.Err = BatchStageLoad, source.Err
+		return result
+	}
+	b, err := NewBuilderFromBytes(opts.Type, opts.Config, source.Data)
+	if err != nil {
+		result.Stage, result.Err = BatchStageLoad, err
+		return result
+	}
+	if err := b.Validate().Err(); err != nil {
+		result.Stage, result.Err = BatchStageValidate, err
+		return result
+	}
+
+	buf, err := b.Generate()
+	if err != nil {
+		result.Stage, result.Err = BatchStageRender, err
+		return result
+	}
+
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, BatchFilenameData{DocumentInfo: b.Info(), Index: ix}); err != nil {
+		result.Stage, result.Err = BatchStageWrite, err
+		return result
+	}
+	result.Output = filepath.Join(opts.OutputDir, outputs.reserve(sanitizeFilename(name.String())))
+	if err := os.WriteFile(result.Output, buf, 0666); err != nil {
+		result.Stage, result.Err = BatchStageWrite, err
+	}
+	return result
+}
+
+// outputNames hands out unique output file names to concurrent workers.
+type outputNames struct {
+	mu    sync.Mutex
+	taken map[string]bool
+}
+
+func newOutputNames() *outputNames {
+	return &outputNames{taken: map[string]bool{}}
+}
+
+func (o *outputNames) reserve(name string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	unique := name
+	for n := 2; o.taken[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+	o.taken[unique] = true
+	return unique
+}
+
+// sanitizeFilename replaces characters that are unsafe in file names.
+func sanitizeFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 0x20 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "document.pdf"
+	}
+	return name
+}
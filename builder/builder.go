@@ -1,14 +1,19 @@
 package builder
 
 import (
+	"fmt"
+	"io/fs"
 	"log"
 	"log/slog"
+	"time"
 
-	"github.com/johnfercher/maroto/v2/pkg/components/page"
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/core/entity"
 	"github.com/johnfercher/maroto/v2/pkg/props"
 	"github.com/quail-ink/bizdocgen/core"
+	"github.com/quail-ink/bizdocgen/einvoice"
 	"github.com/quail-ink/bizdocgen/i18n"
+	"github.com/quail-ink/bizdocgen/pdfa"
 )
 
 type (
@@ -20,6 +25,63 @@ type (
 		FontBoldItalic string
 
 		Lang string
+
+		// CustomFonts are fonts preloaded with LoadFonts. When set, the font
+		// files above are not read again.
+		CustomFonts []*entity.CustomFont
+
+		// Seals maps CompanySeal and CompanyLogo values of params to
+		// preloaded images. When set, images are never read from files.
+		Seals map[string][]byte
+
+		// Assets is the filesystem CompanySeal and CompanyLogo paths of
+		// params are read from, such as an embed.FS, instead of the local
+		// filesystem.
+		Assets fs.FS
+
+		// Logo is the placement and size of the CompanyLogo of params.
+		Logo LogoPlacement
+
+		// Seal is the placement, size and opacity of the CompanySeal of
+		// params, and the stamp generated when they have none.
+		Seal SealPlacement
+
+		// StrictValidation refuses to generate documents whose params fail
+		// validation.
+		StrictValidation bool
+
+		// FacturX embeds the invoice as Cross Industry Invoice XML of the
+		// given profile into generated invoices, producing Factur-X /
+		// ZUGFeRD hybrid PDFs. Only invoices are affected.
+		FacturX einvoice.CIIProfile
+
+		// PDFA produces PDF/A documents of the given level for archival,
+		// with an sRGB output intent and XMP metadata derived from params.
+		// PDF/A requires embedded fonts, so custom fonts must be configured.
+		PDFA pdfa.Level
+
+		// Metadata overrides the PDF metadata derived from params. Empty
+		// fields keep the derived values.
+		Metadata DocumentMetadata
+
+		// Footer maps languages to markdown-lite text rendered at the
+		// bottom of every page, such as company registration lines. The
+		// footer of "" is used for languages without their own.
+		Footer map[string]string
+
+		// Holidays are the days besides weekends that due dates computed
+		// from payment terms are moved off, by the business_day rule of
+		// invoices.
+		Holidays []time.Time
+
+		// AmountInWords writes the total out in words under the summary of
+		// invoices and payment statements and the amount of receipts,
+		// Japanese amounts in Numerals, NumeralsKanji by default.
+		AmountInWords bool
+		Numerals      i18n.Numerals
+
+		// Theme is the look of the documents, DefaultTheme when nil.
+		Theme *Theme
 	}
 
 	Builder struct {
@@ -27,29 +89,37 @@ type (
 		i18nBundle       *i18n.I18nBundle
 		iParams          *core.InvoiceParams
 		psParams         *core.PaymentStatementParams
+		qParams          *core.QuoteParams
+		rParams          *core.ReceiptParams
+		cnParams         *core.CreditNoteParams
 		Round            int32
 		fgColor          *props.Color
 		fgSecondaryColor *props.Color
+		measurements     *textMeasurer
 	}
 )
 
-func NewInvoiceBuilder(cfg Config, params *core.InvoiceParams) (*Builder, error) {
-	i18nBundle := i18n.New()
+// newBuilder creates a builder for documents of any type, with the colours
+// of the theme.
+func newBuilder(cfg Config) *Builder {
 	if cfg.Lang == "" {
 		cfg.Lang = "en"
 	}
-	round := 2
-	if params.Currency == "JPY" || params.Currency == "円" {
-		round = 0
+	b := &Builder{
+		cfg:        cfg,
+		i18nBundle: i18n.New(),
 	}
-	return &Builder{
-		cfg:              cfg,
-		i18nBundle:       i18nBundle,
-		iParams:          params,
-		Round:            int32(round),
-		fgColor:          &props.Color{Red: 50, Green: 50, Blue: 93},
-		fgSecondaryColor: &props.Color{Red: 80, Green: 80, Blue: 123},
-	}, nil
+	palette := b.theme().Palette
+	b.fgColor = &palette.Text
+	b.fgSecondaryColor = &palette.SecondaryText
+	return b
+}
+
+func NewInvoiceBuilder(cfg Config, params *core.InvoiceParams) (*Builder, error) {
+	b := newBuilder(cfg)
+	b.iParams = params
+	b.Round = core.CurrencyPlaces(params.Currency)
+	return b, nil
 }
 
 func NewInvoiceBuilderFromFile(cfg Config, filename string) (*Builder, error) {
@@ -61,20 +131,10 @@ func NewInvoiceBuilderFromFile(cfg Config, filename string) (*Builder, error) {
 }
 
 func NewPaymentStatementBuilder(cfg Config, params *core.PaymentStatementParams) (*Builder, error) {
-	i18nBundle := i18n.New()
-	if cfg.Lang == "" {
-		cfg.Lang = "en"
-	}
-	round := 2
-	if params.Currency == "JPY" || params.Currency == "円" {
-		round = 0
-	}
-	return &Builder{
-		cfg:        cfg,
-		i18nBundle: i18nBundle,
-		psParams:   params,
-		Round:      int32(round),
-	}, nil
+	b := newBuilder(cfg)
+	b.psParams = params
+	b.Round = core.CurrencyPlaces(params.Currency)
+	return b, nil
 }
 
 func NewPaymentStatementBuilderFromFile(cfg Config, filename string) (*Builder, error) {
@@ -85,75 +145,318 @@ func NewPaymentStatementBuilderFromFile(cfg Config, filename string) (*Builder,
 	return NewPaymentStatementBuilder(cfg, params)
 }
 
-func (b *Builder) GenerateInvoice() ([]byte, error) {
-	headers, err := b.BuildInvoiceHeader()
+// NewQuoteBuilder creates a builder for a quote. The quote is rendered with
+// the invoice sections, so the builder keeps an invoice view of the params.
+func NewQuoteBuilder(cfg Config, params *core.QuoteParams) (*Builder, error) {
+	b, err := NewInvoiceBuilder(cfg, params.ToInvoiceParams(params.ID, params.Date))
 	if err != nil {
-		log.Printf("failed to build invoice header: %v\n", err)
 		return nil, err
 	}
+	b.qParams = params
+	b.iParams.Payment.Disabled = true
+	return b, nil
+}
+
+func NewQuoteBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.QuoteParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewQuoteBuilder(cfg, params)
+}
 
-	m, err := b.CreateMetricsDecorator(headers)
+// NewCreditNoteBuilder creates a builder for a credit note, rendered with the
+// invoice sections like a quote.
+func NewCreditNoteBuilder(cfg Config, params *core.CreditNoteParams) (*Builder, error) {
+	b, err := NewInvoiceBuilder(cfg, params.ToInvoiceParams())
 	if err != nil {
-		log.Printf("failed to register header: %v\n", err)
 		return nil, err
 	}
+	b.cnParams = params
+	return b, nil
+}
 
-	newPage := page.New()
+func NewCreditNoteBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.CreditNoteParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewCreditNoteBuilder(cfg, params)
+}
 
-	receiveRows := b.BuildInvoiceBillTo()
-	newPage.Add(receiveRows...)
+func NewReceiptBuilder(cfg Config, params *core.ReceiptParams) (*Builder, error) {
+	b := newBuilder(cfg)
+	b.rParams = params
+	b.Round = core.CurrencyPlaces(params.Currency)
+	return b, nil
+}
 
-	summary := b.BuildInvoiceSummaryRows()
+func NewReceiptBuilderFromFile(cfg Config, filename string) (*Builder, error) {
+	params := &core.ReceiptParams{}
+	if err := params.Load(filename); err != nil {
+		return nil, err
+	}
+	return NewReceiptBuilder(cfg, params)
+}
 
-	newPage.Add(summary...)
+// Validate checks the params of the document the builder generates. With
+// FacturX set, invoices are also checked against the Factur-X profile.
+func (b *Builder) Validate() *core.ValidationReport {
+	switch b.DocumentType() {
+	case DocumentQuote:
+		return b.qParams.Validate()
+	case DocumentCreditNote:
+		return b.cnParams.Validate()
+	case DocumentReceipt:
+		return b.rParams.Validate()
+	case DocumentPaymentStatement:
+		return b.psParams.Validate()
+	default:
+		r := b.iParams.Validate()
+		if b.cfg.FacturX != "" {
+			r.Errors = append(r.Errors, einvoice.ValidateCII(b.iParams, b.cfg.FacturX).Errors...)
+		}
+		return r
+	}
+}
 
-	details := b.BuildInvoiceDetailsRows()
+// checkParams returns the validation errors of the params when strict
+// validation is enabled.
+func (b *Builder) checkParams() error {
+	if !b.cfg.StrictValidation {
+		return nil
+	}
+	if err := b.Validate().Err(); err != nil {
+		log.Printf("params failed validation: %v\n", err)
+		return err
+	}
+	return nil
+}
 
-	newPage.Add(details...)
+func (b *Builder) GenerateInvoice() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
 
-	if !b.iParams.Payment.Disabled {
-		payment := b.BuildInvoicePaymentRows()
-		newPage.Add(payment...)
+	if b.iParams.QualifiedInvoice {
+		if err := b.iParams.ValidateQualifiedInvoice(); err != nil {
+			log.Printf("invoice is not a valid qualified invoice: %v\n", err)
+			return nil, err
+		}
 	}
 
-	m.AddPages(newPage)
+	if b.iParams.Summary.Compute {
+		if err := b.iParams.CheckSummary(b.Round); err != nil {
+			log.Printf("invoice summary is inconsistent: %v\n", err)
+			return nil, err
+		}
+	}
+
+	if err := b.checkFacturX(); err != nil {
+		return nil, err
+	}
+
+	headers, err := b.BuildInvoiceHeader()
+	if err != nil {
+		log.Printf("failed to build invoice header: %v\n", err)
+		return nil, err
+	}
+
+	body, err := b.buildBody()
+	if err != nil {
+		return nil, err
+	}
 
-	return b.getBytesFromMaroto(m)
+	return b.render(headers, body)
 }
 
 func (b *Builder) GeneratePaymentStatement() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+
 	headers, err := b.BuildPsHeader()
 	if err != nil {
 		log.Printf("failed to build header: %v\n", err)
 		return nil, err
 	}
 
-	m, err := b.CreateMetricsDecorator(headers)
+	body, err := b.buildBody()
 	if err != nil {
-		log.Printf("failed to register header: %v\n", err)
 		return nil, err
 	}
 
-	newPage := page.New()
+	return b.render(headers, body)
+}
 
-	payer := b.BuildPsPayer()
-	newPage.Add(payer...)
+func (b *Builder) GenerateQuote() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+
+	if b.iParams.Summary.Compute {
+		if err := b.iParams.CheckSummary(b.Round); err != nil {
+			log.Printf("quote summary is inconsistent: %v\n", err)
+			return nil, err
+		}
+	}
+
+	headers, err := b.BuildInvoiceHeader()
+	if err != nil {
+		log.Printf("failed to build quote header: %v\n", err)
+		return nil, err
+	}
 
-	payee := b.BuildPsPayee()
-	newPage.Add(payee...)
+	body, err := b.buildBody()
+	if err != nil {
+		return nil, err
+	}
 
-	channel := b.BuildPsChannelRows()
-	newPage.Add(channel...)
+	return b.render(headers, body)
+}
 
-	summary := b.BuildPsSummaryRows()
-	newPage.Add(summary...)
+func (b *Builder) GenerateCreditNote() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
 
-	details := b.BuildPsDetailsRows()
-	newPage.Add(details...)
+	if b.iParams.Summary.Compute {
+		if err := b.iParams.CheckSummary(b.Round); err != nil {
+			log.Printf("credit note summary is inconsistent: %v\n", err)
+			return nil, err
+		}
+	}
 
-	m.AddPages(newPage)
+	headers, err := b.BuildInvoiceHeader()
+	if err != nil {
+		log.Printf("failed to build credit note header: %v\n", err)
+		return nil, err
+	}
 
-	return b.getBytesFromMaroto(m)
+	body, err := b.buildBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.render(headers, body)
+}
+
+func (b *Builder) GenerateReceipt() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+
+	headers, err := b.BuildReceiptHeader()
+	if err != nil {
+		log.Printf("failed to build receipt header: %v\n", err)
+		return nil, err
+	}
+
+	body, err := b.buildBody()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.render(headers, body)
+}
+
+// buildBody lays out the sections of the document below the page header,
+// in the order of the theme. Sections the document has no content for are
+// skipped.
+func (b *Builder) buildBody() (*layout, error) {
+	docType := b.DocumentType()
+	body := &layout{}
+	for _, section := range b.sections() {
+		switch section {
+		case SectionBillTo:
+			if b.iParams != nil {
+				body.keep(b.BuildInvoiceBillTo()...)
+			}
+		case SectionReason:
+			if b.cnParams != nil && b.cnParams.Reason != "" {
+				body.keep(b.BuildCreditNoteReasonRows()...)
+			}
+		case SectionSummary:
+			if docType == DocumentPaymentStatement {
+				body.keep(b.BuildPsSummaryRows()...)
+			} else if b.iParams != nil {
+				body.keep(b.BuildInvoiceSummaryRows()...)
+			}
+		case SectionDetails:
+			switch {
+			case docType == DocumentPaymentStatement:
+				body.table(b.psDetailsTable())
+			case docType == DocumentReceipt:
+				body.keep(b.BuildReceiptDetailsRows()...)
+			default:
+				body.table(b.invoiceDetailsTable())
+			}
+		case SectionPayment:
+			if b.iParams != nil && !b.iParams.Payment.Disabled {
+				body.keep(b.BuildInvoicePaymentRows()...)
+			}
+		case SectionNotes:
+			if notes := b.notes(); notes != "" {
+				body.keepEach(b.markupSection(b.i18nBundle.MusT(b.cfg.Lang, "Notes", nil), notes))
+			}
+		case SectionTerms:
+			if b.qParams != nil && b.qParams.Terms != "" {
+				body.keepEach(b.quoteTermsSection())
+			} else if terms := b.terms(); terms != "" {
+				body.keepEach(b.markupSection(b.i18nBundle.MusT(b.cfg.Lang, "Terms", nil), terms))
+			}
+		case SectionPayer:
+			if b.psParams != nil {
+				body.keep(b.BuildPsPayer()...)
+			}
+		case SectionPayee:
+			if b.psParams != nil {
+				body.keep(b.BuildPsPayee()...)
+			}
+		case SectionChannel:
+			if b.psParams != nil {
+				body.keep(b.BuildPsChannelRows()...)
+			}
+		case SectionAmount:
+			if b.rParams != nil {
+				body.keep(b.BuildReceiptAmountRows()...)
+			}
+		case SectionIssuer:
+			if b.rParams != nil {
+				issuer, err := b.BuildReceiptIssuerRows()
+				if err != nil {
+					log.Printf("failed to build receipt issuer: %v\n", err)
+					return nil, err
+				}
+				body.keep(issuer...)
+			}
+		default:
+			return nil, fmt.Errorf("unknown section %q", section)
+		}
+	}
+	return body, nil
+}
+
+// notes returns the notes of invoices and payment statements.
+func (b *Builder) notes() string {
+	switch {
+	case b.psParams != nil:
+		return b.psParams.Notes
+	case b.iParams != nil && b.qParams == nil:
+		return b.iParams.Notes
+	}
+	return ""
+}
+
+// terms returns the terms of invoices and payment statements.
+func (b *Builder) terms() string {
+	switch {
+	case b.psParams != nil:
+		return b.psParams.Terms
+	case b.iParams != nil && b.qParams == nil:
+		return b.iParams.Terms
+	}
+	return ""
 }
 
 func (b *Builder) getBytesFromMaroto(maroto marotoCore.Maroto) ([]byte, error) {
@@ -163,6 +466,5 @@ func (b *Builder) getBytesFromMaroto(maroto marotoCore.Maroto) ([]byte, error) {
 		return nil, err
 	}
 
-	bytes := document.GetBytes()
-	return bytes, nil
+	return b.postProcess(document.GetBytes())
 }
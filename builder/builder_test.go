@@ -1,8 +1,36 @@
 package builder
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core/entity"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/quail-ink/bizdocgen/einvoice"
+	"github.com/quail-ink/bizdocgen/i18n"
+	"github.com/quail-ink/bizdocgen/pdfa"
+	"github.com/shopspring/decimal"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
 )
 
 // TestHelloName calls greetings.Hello with a name, checking
@@ -56,6 +84,912 @@ func TestGenerateInvoiceWithConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateInvoiceItemized(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{}, "../sample-params/invoice-3.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	item := builder.iParams.DetailItems[0]
+	if !item.LineTotal().Equal(decimal.NewFromInt(3400)) {
+		t.Fatalf("unexpected line total: %s", item.LineTotal())
+		return
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate invoice")
+		return
+	}
+
+	filename := "../sample-invoice-itemized.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+}
+
+func TestGenerateInvoiceMixedItems(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{}, "../sample-params/invoice-3.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	// totals-only items among itemized ones are shown excluding tax too
+	builder.iParams.DetailItems = append(builder.iParams.DetailItems,
+		core.InvoiceDetailItem{Title: "Gross only", TotalIncludeTax: decimal.NewFromInt(1100)},
+		core.InvoiceDetailItem{Title: "Both totals", TotalExcludeTax: decimal.NewFromInt(2000), TotalIncludeTax: decimal.NewFromInt(2200)},
+	)
+	// the summary is computed from the items
+	builder.iParams.Summary.TotalExcludeTax = decimal.Zero
+	n := len(builder.iParams.DetailItems)
+	for ix, want := range map[int]string{0: "3400 USD", n - 2: "1000 USD", n - 1: "2000 USD"} {
+		if got := builder.itemAmountText(builder.iParams.DetailItems[ix], true); got != want {
+			t.Fatalf("item %d: expected %q, got %q", ix, want, got)
+		}
+	}
+	// tables without itemized items keep the totals including tax
+	if got := builder.itemAmountText(builder.iParams.DetailItems[n-1], false); got != "2200 USD" {
+		t.Fatalf("expected the total including tax, got %q", got)
+	}
+	if buf, err := builder.GenerateInvoice(); buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+	}
+	buf, err := builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatalf("failed to generate invoice HTML: %v", err)
+	}
+	for _, want := range []string{"3400 USD", "1000 USD", "2000 USD"} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the HTML to contain %q", want)
+		}
+	}
+}
+
+func TestGenerateInvoiceMixedTaxRates(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{}, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate invoice")
+		return
+	}
+
+	filename := "../sample-invoice-mixed-tax.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+}
+
+func TestGenerateInvoiceMultiPage(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{}, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	items := builder.iParams.DetailItems
+	for len(builder.iParams.DetailItems) < 60 {
+		builder.iParams.DetailItems = append(builder.iParams.DetailItems, items...)
+	}
+
+	cfg, err := builder.marotoConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := builder.BuildInvoiceHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	height := cfg.Dimensions.Height - cfg.Margins.Top - cfg.Margins.Bottom
+	details := builder.invoiceDetailsTable()
+	body := &layout{}
+	body.keep(builder.BuildInvoiceBillTo()...)
+	body.keep(builder.BuildInvoiceSummaryRows()...)
+	body.table(details)
+	body.keep(builder.BuildInvoicePaymentRows()...)
+	pages := body.paginate(height, rowsHeight(head))
+	if len(pages) < 2 {
+		t.Fatalf("expected several pages, got %d", len(pages))
+	}
+	continued := rowsHeight(details.header(true))
+	for ix, p := range pages {
+		rows := p.GetRows()
+		if rowsHeight(head)+rowsHeight(rows) >= height {
+			t.Fatalf("page %d overflows", ix+1)
+		}
+		if ix > 0 && ix < len(pages)-1 && rowsHeight(rows[:len(details.header(true))]) != continued {
+			t.Fatalf("page %d does not start with the table header", ix+1)
+		}
+		// every item ends with a spacer row, so no page but the last may end
+		// in the middle of an item
+		if last := rows[len(rows)-1]; ix < len(pages)-1 && last.GetHeight() != builder.theme().Spacing.Item {
+			t.Fatalf("page %d splits a detail item", ix+1)
+		}
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate invoice")
+		return
+	}
+	n, err := api.PageCount(bytes.NewReader(buf), model.NewDefaultConfiguration())
+	if err != nil || n != len(pages) {
+		t.Fatalf("expected %d pages, got %d: %v", len(pages), n, err)
+	}
+
+	filename := "../sample-invoice-multipage.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+}
+
+func TestGenerateInvoiceNotesAndFooter(t *testing.T) {
+	cfg := Config{Footer: map[string]string{
+		"":   "**ABC Inc** · Registered in Delaware, No. 1234567",
+		"ja": "**ABC株式会社** 登録番号 T1234567890123",
+	}}
+	builder, err := NewInvoiceBuilderFromFile(cfg, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	builder.iParams.Notes = "Thank you for your business!"
+	builder.iParams.Terms = "**Payment terms:** net 30 days.\n\n- Late payments bear interest of 1.5% per month.\n- Bank charges are borne by the payer."
+
+	if rows := builder.BuildFooterRows(); len(rows) != 2 {
+		t.Fatalf("expected a border and one line of footer, got %d rows", len(rows))
+	}
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	filename := "../sample-invoice-notes.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+
+	buf, err = builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<p>Thank you for your business!</p>",
+		"<strong>Payment terms:</strong> net 30 days.",
+		"<ul><li>Late payments bear interest of 1.5% per month.</li><li>",
+		"<footer class=\"secondary markup\"><p><strong>ABC Inc</strong>",
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the HTML to contain %q", want)
+		}
+	}
+
+	builder.cfg.Lang = "ja"
+	if footer := builder.footer(); footer != cfg.Footer["ja"] {
+		t.Fatalf("unexpected footer %q", footer)
+	}
+}
+
+func TestCompanyLogo(t *testing.T) {
+	logo, err := os.ReadFile("../sample-logo.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder, err := NewInvoiceBuilderFromFile(Config{Logo: LogoPlacement{Align: align.Right}}, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	builder.iParams.CompanyLogo = "../sample-logo.svg"
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	filename := "../sample-invoice-logo.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+
+	buf, err = builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"text-align: right",
+		"xml;base64," + base64.StdEncoding.EncodeToString(logo),
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the HTML to contain %q", want)
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+	pngBuf, jpegBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := png.Encode(pngBuf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(jpegBuf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	assets := fstest.MapFS{
+		"logo.png":  {Data: pngBuf.Bytes()},
+		"logo.jpg":  {Data: jpegBuf.Bytes()},
+		"logo.svg":  {Data: logo},
+		"logo.webp": {Data: []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\r\x00\x00\x00/\x00\x00\x00\x10\x07\x10\x11\x11\x88\x88\xfe\x07\x00")},
+		"logo.gif":  {Data: []byte("GIF89a")},
+	}
+	builder, err = NewPaymentStatementBuilderFromFile(Config{Assets: assets}, "../sample-params/paymentstatement-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	builder.psParams.CompanySeal = "logo.png"
+	for name, want := range map[string]string{
+		"logo.png":  "image/png",
+		"logo.jpg":  "image/jpeg",
+		"logo.svg":  "image/svg+xml",
+		"logo.webp": "image/webp",
+		"data:image/png;base64," + base64.StdEncoding.EncodeToString(pngBuf.Bytes()): "image/png",
+	} {
+		img, err := builder.loadImage(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if img.mime != want || img.width == 0 || img.height == 0 {
+			t.Fatalf("%s: unexpected %s image of %dx%d", name, img.mime, img.width, img.height)
+		}
+		builder.psParams.CompanyLogo = name
+		if buf, err := builder.GeneratePaymentStatement(); buf == nil || err != nil {
+			t.Fatalf("failed to generate payment statement with %s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"logo.gif", "missing.png", "data:image/png,raw"} {
+		if _, err := builder.loadImage(name); err == nil {
+			t.Fatalf("expected %s to fail", name)
+		}
+	}
+}
+
+func TestCompanySeal(t *testing.T) {
+	cfg := Config{Seal: SealPlacement{Size: 20, Opacity: 0.5, Stamp: StampCircle}}
+	builder, err := NewInvoiceBuilderFromFile(cfg, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	builder.iParams.CompanySeal = ""
+
+	seal, err := builder.loadSeal("", builder.iParams.CompanyName)
+	if seal == nil || err != nil {
+		t.Fatalf("expected a generated seal: %v", err)
+	}
+	rect := builder.sealRect(seal, 95, 42, builder.iParams.CompanyName, 8, 14)
+	nameWidth := builder.measurer().measure(builder.iParams.CompanyName, true, 14)
+	if math.Abs(rect.Left-(nameWidth-10)) > 0.01 || math.Abs(rect.Top-(8+14*ptToMM/2-10)) > 0.01 {
+		t.Fatalf("expected the seal centred on the end of the name, got %+v", rect)
+	}
+	if want := 20.0 / 42 * 100; math.Abs(rect.Percent-want) > 0.01 {
+		t.Fatalf("expected the seal to be 20mm wide, got %v%%", rect.Percent)
+	}
+
+	faded, err := seal.withOpacity(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(faded.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := decoded.At(stampSize/2, 3).RGBA(); a == 0 || a > 0x8080 {
+		t.Fatalf("expected the border to be half transparent, got alpha %x", a)
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	filename := "../sample-invoice-stamp.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+	buf, err = builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), "width: 20mm; opacity: 0.5;") {
+		t.Fatal("expected the HTML seal to be sized and faded")
+	}
+
+	builder.cfg.Seal = SealPlacement{Anchor: SealAnchorBlock, Left: 30, Top: 5, Stamp: StampSquare}
+	if rect := builder.sealRect(seal, 95, 42, builder.iParams.CompanyName, 8, 14); rect.Left != 30 || rect.Top != 5 {
+		t.Fatalf("expected the seal at the offset of the issuer block, got %+v", rect)
+	}
+	if buf, err := builder.GenerateInvoice(); buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"ABC Inc": "ABC|Inc",
+		"春日町株式会社": "春日町|株式会|社",
+		"株式会社 ＡＢ": "株式会|社ＡＢ",
+	} {
+		lines := []string{}
+		for _, line := range stampLines(name) {
+			lines = append(lines, string(line))
+		}
+		if got := strings.Join(lines, "|"); got != want {
+			t.Fatalf("%s: expected the lines %s, got %s", name, want, got)
+		}
+	}
+}
+
+func TestParseMarkup(t *testing.T) {
+	blocks := parseMarkup("Intro **bold** text\nsecond line\n\n- one\n  continued\n* two **unclosed\nafter")
+	want := []markupBlock{
+		{Lines: [][]markupSpan{
+			{{Text: "Intro "}, {Text: "bold", Bold: true}, {Text: " text"}},
+			{{Text: "second line"}},
+		}},
+		{Bullet: true, Lines: [][]markupSpan{{{Text: "one"}}, {{Text: "continued"}}}},
+		{Bullet: true, Lines: [][]markupSpan{{{Text: "two **unclosed"}}}},
+		{Lines: [][]markupSpan{{{Text: "after"}}}},
+	}
+	if fmt.Sprint(blocks) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, blocks)
+	}
+
+	builder, _ := NewInvoiceBuilderFromFile(Config{}, "../sample-params/invoice-1.yaml")
+	m := builder.measurer()
+	long := strings.Repeat("late fees apply ", 30) + strings.Repeat("支払期日", 40)
+	lines := m.wrap([]markupSpan{{Text: long}, {Text: " end", Bold: true}}, 100, 8)
+	if len(lines) < 3 {
+		t.Fatalf("expected the text to wrap, got %d lines", len(lines))
+	}
+	for _, line := range lines {
+		width := 0.0
+		for _, span := range line {
+			width += m.measure(span.Text, span.Bold, 8)
+		}
+		if width > 100+m.measure(" ", false, 8) {
+			t.Fatalf("line %v is %.1fmm wide", line, width)
+		}
+	}
+}
+
+func TestThemes(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := ThemeByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		builder, err := NewInvoiceBuilderFromFile(Config{Theme: &theme}, "../sample-params/invoice-1.yaml")
+		if err != nil {
+			t.Fatal("failed to create builder")
+			return
+		}
+		if buf, err := builder.GenerateInvoice(); buf == nil || err != nil {
+			t.Fatalf("failed to generate invoice with the %s theme: %v", name, err)
+		}
+	}
+	if _, err := ThemeByName("neon"); err == nil {
+		t.Fatal("expected an unknown theme to fail")
+	}
+
+	theme := MonochromeTheme()
+	theme.Sections = map[DocumentType][]Section{
+		DocumentInvoice: {SectionDetails, SectionSummary},
+	}
+	builder, err := NewInvoiceBuilderFromFile(Config{Theme: &theme}, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	buf, err := builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(buf)
+	details, summary := strings.Index(html, "<h2>Details</h2>"), strings.Index(html, "<span>Summary</span>")
+	if details < 0 || summary < 0 || details > summary {
+		t.Fatal("expected the details before the summary")
+	}
+	for _, unwanted := range []string{"<h2>Payment Instructions</h2>", builder.iParams.BillToCompany} {
+		if strings.Contains(html, unwanted) {
+			t.Fatalf("expected the HTML not to contain %q", unwanted)
+		}
+	}
+	if !strings.Contains(html, "--rule: 1px dashed rgb(120, 120, 120);") {
+		t.Fatal("expected the theme CSS variables")
+	}
+
+	theme.Sections[DocumentInvoice] = []Section{"cover"}
+	if _, err := builder.GenerateInvoice(); err == nil {
+		t.Fatal("expected an unknown section to fail")
+	}
+}
+
+func TestInvoiceDueDate(t *testing.T) {
+	holidays := []time.Time{time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)}
+	builder, err := NewInvoiceBuilderFromFile(Config{Lang: "ja", Holidays: holidays}, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	builder.iParams.Status = core.InvoiceStatusOverdue
+
+	// the end of June 2024 is a Sunday and the Friday before a holiday
+	if due := builder.dueDateText(); due != "2024/06/27" {
+		t.Fatalf("expected the due date to be moved to 2024/06/27, got %s", due)
+	}
+	if terms := builder.paymentTermsText(); terms != "月末締め翌月末払い" {
+		t.Fatalf("expected the localised payment terms, got %s", terms)
+	}
+	if rows := builder.BuildInvoiceStatusRows(); len(rows) == 0 {
+		t.Fatal("expected the overdue stamp")
+	}
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	filename := "../sample-invoice-overdue.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+
+	buf, err = builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"お支払期限: 2024/06/27", `<div class="status overdue"><span>支払期限超過</span></div>`, "--overdue: rgb(200, 34, 34);"} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the HTML to contain %q", want)
+		}
+	}
+
+	builder.cfg.Lang = "en"
+	builder.iParams.Status = core.InvoiceStatusPaid
+	builder.iParams.PaymentTerms = "day-10+2"
+	if terms := builder.paymentTermsText(); terms != "Day 10 of the month 2 months later" {
+		t.Fatalf("expected the localised payment terms, got %s", terms)
+	}
+	if label, _ := builder.invoiceStatus(); label != "PAID" {
+		t.Fatalf("expected the paid stamp, got %q", label)
+	}
+}
+
+func TestAmountInWords(t *testing.T) {
+	cfg := Config{Lang: "ja", AmountInWords: true, Numerals: i18n.NumeralsDaiji}
+	builder, err := NewInvoiceBuilderFromFile(cfg, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	total := builder.iParams.Totals(builder.Round).Total
+	words := builder.amountInWords(total, builder.iParams.Currency)
+	if !strings.HasPrefix(words, "金") || !strings.HasSuffix(words, "円也") {
+		t.Fatalf("expected the total in daiji, got %q", words)
+	}
+	if buf, err := builder.GenerateInvoice(); buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+	}
+	buf, err := builder.GenerateInvoiceHTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf), words) {
+		t.Fatalf("expected the HTML to contain %q", words)
+	}
+
+	builder, err = NewPaymentStatementBuilderFromFile(Config{AmountInWords: true}, "../sample-params/paymentstatement-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	if rows := builder.BuildPsSummaryRows(); len(rows) <= 4 {
+		t.Fatal("expected the net amount in words")
+	}
+	if buf, err := builder.GeneratePaymentStatement(); buf == nil || err != nil {
+		t.Fatalf("failed to generate payment statement: %v", err)
+	}
+
+	builder, err = NewReceiptBuilderFromFile(Config{AmountInWords: true}, "../sample-params/receipt-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	if buf, err := builder.GenerateReceipt(); buf == nil || err != nil {
+		t.Fatalf("failed to generate receipt: %v", err)
+	}
+
+	builder.cfg.AmountInWords = false
+	if words := builder.amountInWords(builder.rParams.Amount, builder.rParams.Currency); words != "" {
+		t.Fatalf("expected no amount in words, got %q", words)
+	}
+}
+
+func TestGenerateInvoiceFacturX(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{FacturX: einvoice.CIIEN16931}, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	if _, err := builder.GenerateInvoice(); err == nil {
+		t.Fatal("expected Factur-X without custom fonts to be refused")
+	}
+
+	fonts := []*entity.CustomFont{
+		{Family: "go", Style: fontstyle.Normal, Bytes: goregular.TTF},
+		{Family: "go", Style: fontstyle.Bold, Bytes: gobold.TTF},
+	}
+	holidays := []time.Time{time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)}
+	builder, err = NewInvoiceBuilderFromFile(Config{FontName: "go", CustomFonts: fonts, FacturX: einvoice.CIIEN16931, Holidays: holidays}, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	report, err := pdfa.Verify(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Part != 3 {
+		t.Fatalf("expected Factur-X to be PDF/A-3b, got %+v", report)
+	}
+
+	attachments, err := api.ExtractAttachmentsRaw(bytes.NewReader(buf), "", nil, model.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attachments) != 1 || attachments[0].FileName != einvoice.FacturXFilename {
+		t.Fatalf("expected a single %s attachment, got %v", einvoice.FacturXFilename, attachments)
+	}
+	xml, err := io.ReadAll(attachments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(xml), "urn:cen.eu:en16931:2017") {
+		t.Fatal("expected the attachment to be EN 16931 CII")
+	}
+	if !strings.Contains(string(xml), `<udt:DateTimeString format="102">20240627</udt:DateTimeString>`) {
+		t.Fatal("expected the due date of the PDF, moved off the holiday")
+	}
+	if !bytes.Contains(buf, []byte("<fx:ConformanceLevel>EN 16931</fx:ConformanceLevel>")) {
+		t.Fatal("expected the XMP metadata to declare the conformance level")
+	}
+
+	builder, err = NewInvoiceBuilderFromFile(Config{FontName: "go", CustomFonts: fonts, FacturX: einvoice.CIIBasic}, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	if _, err := builder.GenerateInvoice(); err == nil {
+		t.Fatal("expected params without countries to be refused")
+	}
+}
+
+func TestGenerateInvoicePDFA(t *testing.T) {
+	fonts := []*entity.CustomFont{
+		{Family: "go", Style: fontstyle.Normal, Bytes: goregular.TTF},
+		{Family: "go", Style: fontstyle.Bold, Bytes: gobold.TTF},
+	}
+	builder, err := NewInvoiceBuilderFromFile(Config{FontName: "go", CustomFonts: fonts, PDFA: pdfa.Level3B, FacturX: einvoice.CIIBasic}, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	report, err := pdfa.Verify(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() || report.Part != 3 {
+		t.Fatalf("expected PDF/A-3b, got %+v", report)
+	}
+	if !bytes.Contains(buf, []byte(documentUUID(DocumentInvoice, "春日町株式会社", "20240510-SAMPLE"))) {
+		t.Fatal("expected the document ID in the XMP metadata")
+	}
+
+	builder, _ = NewInvoiceBuilderFromFile(Config{PDFA: pdfa.Level2B}, "../sample-params/invoice-4.yaml")
+	if _, err := builder.GenerateInvoice(); err == nil {
+		t.Fatal("expected PDF/A without custom fonts to be refused")
+	}
+	builder, _ = NewInvoiceBuilderFromFile(Config{FontName: "go", CustomFonts: fonts, PDFA: pdfa.Level2B, FacturX: einvoice.CIIBasic}, "../sample-params/invoice-4.yaml")
+	if _, err := builder.GenerateInvoice(); err == nil {
+		t.Fatal("expected Factur-X in PDF/A-2 to be refused")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{}, "../sample-params/invoice-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+	if name := builder.SuggestedFilename("pdf"); name != "invoice-20240210-SAMPLE.pdf" {
+		t.Fatalf("unexpected file name %s", name)
+	}
+
+	buf, err := builder.GenerateInvoice()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate invoice")
+		return
+	}
+	ctx, err := api.ReadContext(bytes.NewReader(buf), model.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"Title":    "Invoice 20240210-SAMPLE – ABC Inc",
+		"Author":   "ABC Inc",
+		"Subject":  "System Development and Design Service",
+		"Keywords": "Invoice, 20240210-SAMPLE, ABC Inc, XYZ LLC",
+		"Creator":  DefaultCreator,
+	} {
+		if got, err := ctx.DereferenceText(info[key]); err != nil || got != want {
+			t.Fatalf("expected %s %q, got %q", key, want, got)
+		}
+	}
+
+	builder, _ = NewInvoiceBuilderFromFile(Config{Metadata: DocumentMetadata{Title: "Custom", Keywords: "a, b"}}, "../sample-params/invoice-1.yaml")
+	if m := builder.Metadata(); m.Title != "Custom" || m.Keywords != "a, b" || m.Author != "ABC Inc" || m.Creator != DefaultCreator {
+		t.Fatalf("unexpected metadata %+v", m)
+	}
+
+	builder, _ = NewInvoiceBuilderFromFile(Config{Lang: "ja"}, "../sample-params/invoice-1.yaml")
+	if m := builder.Metadata(); m.Title != "請求書 20240210-SAMPLE – ABC Inc" || !strings.HasPrefix(m.Keywords, "請求書, ") {
+		t.Fatalf("unexpected metadata %+v", m)
+	}
+	builder.iParams.ID = ""
+	if m := builder.Metadata(); m.Title != "請求書 – ABC Inc" {
+		t.Fatalf("unexpected title %q", m.Title)
+	}
+	builder.iParams.CompanyName = ""
+	if m := builder.Metadata(); m.Title != "請求書" {
+		t.Fatalf("unexpected title %q", m.Title)
+	}
+}
+
+func TestGenerateQuote(t *testing.T) {
+	builder, err := NewQuoteBuilderFromFile(Config{}, "../sample-params/quote-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	buf, err := builder.GenerateQuote()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate quote")
+		return
+	}
+
+	filename := "../sample-quote.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+
+	var mismatch *core.SummaryMismatchError
+	builder.iParams.Summary.TotalExcludeTax = decimal.NewFromInt(1)
+	if _, err := builder.GenerateQuote(); !errors.As(err, &mismatch) {
+		t.Fatalf("expected an inconsistent quote summary to be refused, got %v", err)
+	}
+}
+
+func TestGenerateReceipt(t *testing.T) {
+	builder, err := NewReceiptBuilderFromFile(Config{Lang: "ja"}, "../sample-params/receipt-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	if !builder.rParams.RequiresRevenueStamp() {
+		t.Fatal("expected the receipt to require a revenue stamp")
+		return
+	}
+
+	buf, err := builder.GenerateReceipt()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate receipt")
+		return
+	}
+
+	filename := "../sample-receipt.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+}
+
+func TestGenerateCreditNote(t *testing.T) {
+	original := &core.InvoiceParams{}
+	if err := original.Load("../sample-params/invoice-3.yaml"); err != nil {
+		t.Fatal("failed to load invoice")
+		return
+	}
+
+	params, err := core.NewCreditNoteFromInvoice(original, "CN-20240420-SAMPLE", time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC), "Refund of unused hours", 0)
+	if err != nil {
+		t.Fatal("failed to create credit note")
+		return
+	}
+
+	builder, err := NewCreditNoteBuilder(Config{}, params)
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	totals := builder.iParams.Totals(builder.Round)
+	if !totals.Total.Equal(decimal.NewFromInt(-3740)) {
+		t.Fatalf("unexpected credit note total: %s", totals.Total)
+		return
+	}
+
+	buf, err := builder.GenerateCreditNote()
+	if buf == nil || err != nil {
+		t.Fatal("failed to generate credit note")
+		return
+	}
+
+	filename := "../sample-creditnote.pdf"
+	if err := os.WriteFile(filename, buf, 0666); err != nil {
+		t.Fatal("failed to write to file")
+		return
+	}
+}
+
+func TestGenerateWithStrictValidation(t *testing.T) {
+	params := &core.PaymentStatementParams{}
+	if err := params.Load("../sample-params/paymentstatement-1.yaml"); err != nil {
+		t.Fatal("failed to load payment statement")
+		return
+	}
+	params.PeriodEnd = params.PeriodStart.AddDate(0, 0, -1)
+
+	builder, err := NewPaymentStatementBuilder(Config{StrictValidation: true}, params)
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	var fieldErrs core.FieldErrors
+	if _, err := builder.GeneratePaymentStatement(); !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "period_end" {
+		t.Fatalf("expected a validation error at period_end, got %v", err)
+		return
+	}
+}
+
+func TestGenerateBatch(t *testing.T) {
+	sources, err := CollectBatchSources("../sample-params/paymentstatement-*.yaml")
+	if err != nil {
+		t.Fatal("failed to collect batch sources")
+		return
+	}
+	sources = append(sources, BatchSource{Name: "broken", Data: []byte("id: [")})
+
+	report, err := GenerateBatch(context.Background(), sources, BatchOptions{
+		Type:             DocumentPaymentStatement,
+		Workers:          2,
+		OutputDir:        t.TempDir(),
+		FilenameTemplate: `{{.ID}}-{{.Date.Format "200601"}}.pdf`,
+	})
+	if err != nil {
+		t.Fatal("failed to generate batch")
+		return
+	}
+
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Source != "broken" || failed[0].Stage != BatchStageLoad {
+		t.Fatalf("expected only the broken source to fail, got %+v", failed)
+		return
+	}
+	if filepath.Base(report.Results[0].Output) != "20240315-SAMPLE-202403.pdf" {
+		t.Fatalf("unexpected output name: %s", report.Results[0].Output)
+		return
+	}
+
+	// a malformed file fails on its own
+	dir := t.TempDir()
+	good, err := os.ReadFile("../sample-params/paymentstatement-1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"a.yaml": good, "b.yaml": []byte("id: ["), "c.yaml": good} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sources, err = CollectBatchSources(dir)
+	if err != nil || len(sources) != 3 {
+		t.Fatalf("expected 3 sources, got %d: %v", len(sources), err)
+	}
+	report, err = GenerateBatch(context.Background(), sources, BatchOptions{Type: DocumentPaymentStatement, Workers: 2, OutputDir: t.TempDir(), FilenameTemplate: "{{.Index}}.pdf"})
+	if err != nil {
+		t.Fatal("failed to generate batch")
+		return
+	}
+	var perr *core.ParseError
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Stage != BatchStageLoad || !errors.As(failed[0].Err, &perr) || perr.Filename != filepath.Join(dir, "b.yaml") {
+		t.Fatalf("expected only b.yaml to fail, got %+v", failed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = GenerateBatch(ctx, sources, BatchOptions{Type: DocumentPaymentStatement, Workers: 2, OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatal("failed to generate batch")
+		return
+	}
+	for _, result := range report.Results {
+		if !errors.Is(result.Err, context.Canceled) || result.Output != "" {
+			t.Fatalf("expected nothing to be rendered once cancelled, got %+v", result)
+		}
+	}
+}
+
+func TestGenerateHTML(t *testing.T) {
+	builder, err := NewInvoiceBuilderFromFile(Config{Lang: "ja"}, "../sample-params/invoice-4.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	buf, err := builder.GenerateInvoiceHTML()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate invoice: %v", err)
+		return
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "data:image/png;base64,", "T1234567890123", "※"} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the HTML to contain %q", want)
+		}
+	}
+
+	builder, err = NewPaymentStatementBuilderFromFile(Config{Lang: "ja"}, "../sample-params/paymentstatement-1.yaml")
+	if err != nil {
+		t.Fatal("failed to create builder")
+		return
+	}
+
+	buf, err = builder.GeneratePaymentStatementHTML()
+	if buf == nil || err != nil {
+		t.Fatalf("failed to generate payment statement: %v", err)
+		return
+	}
+	if !strings.Contains(string(buf), "data:image/png;base64,") {
+		t.Fatal("expected the seal to be embedded")
+	}
+}
+
 func TestGeneratePaymentstatement(t *testing.T) {
 	builder, err := NewPaymentStatementBuilderFromFile(Config{
 		FontName:       "noto-sans-cjk",
@@ -82,3 +1016,23 @@ func TestGeneratePaymentstatement(t *testing.T) {
 		return
 	}
 }
+
+func TestJSONSchema(t *testing.T) {
+	for _, docType := range DocumentTypes {
+		schema, err := JSONSchema(docType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		committed, err := os.ReadFile(fmt.Sprintf("../schema/%s.schema.json", docType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(append(buf, '\n')) != string(committed) {
+			t.Fatalf("schema/%s.schema.json is out of date, run go generate ./builder", docType)
+		}
+	}
+}
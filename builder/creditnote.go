@@ -0,0 +1,22 @@
+package builder
+
+import (
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+func (b *Builder) BuildCreditNoteReasonRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	tReason := b.i18nBundle.MusT(b.cfg.Lang, "CreditNoteReason", nil)
+
+	return []marotoCore.Row{
+		text.NewRow(8, tReason, props.Text{Size: sizes.Subheading, Top: 0, Style: fontstyle.Bold, Color: b.fgColor}),
+		row.New(10).Add(
+			text.NewCol(12, b.cnParams.Reason, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
+		),
+	}
+}
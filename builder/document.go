@@ -0,0 +1,172 @@
+package builder
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/core"
+)
+
+type DocumentType string
+
+const (
+	DocumentInvoice          DocumentType = "invoice"
+	DocumentPaymentStatement DocumentType = "statement"
+	DocumentQuote            DocumentType = "quote"
+	DocumentReceipt          DocumentType = "receipt"
+	DocumentCreditNote       DocumentType = "creditnote"
+)
+
+// DocumentInfo identifies a generated document.
+type DocumentInfo struct {
+	Type DocumentType
+	ID   string
+	Date time.Time
+	// Party is the counterparty the document is addressed to.
+	Party string
+}
+
+// DocumentTypes lists every document type the builder can generate.
+var DocumentTypes = []DocumentType{
+	DocumentInvoice,
+	DocumentPaymentStatement,
+	DocumentQuote,
+	DocumentReceipt,
+	DocumentCreditNote,
+}
+
+// NewBuilderFromFile creates a builder for the given document type from a
+// params file.
+func NewBuilderFromFile(docType DocumentType, cfg Config, filename string) (*Builder, error) {
+	switch docType {
+	case DocumentInvoice:
+		return NewInvoiceBuilderFromFile(cfg, filename)
+	case DocumentPaymentStatement:
+		return NewPaymentStatementBuilderFromFile(cfg, filename)
+	case DocumentQuote:
+		return NewQuoteBuilderFromFile(cfg, filename)
+	case DocumentReceipt:
+		return NewReceiptBuilderFromFile(cfg, filename)
+	case DocumentCreditNote:
+		return NewCreditNoteBuilderFromFile(cfg, filename)
+	}
+	return nil, fmt.Errorf("unknown document type %q", docType)
+}
+
+// NewBuilderFromBytes creates a builder for the given document type from
+// YAML or JSON params data.
+func NewBuilderFromBytes(docType DocumentType, cfg Config, data []byte) (*Builder, error) {
+	switch docType {
+	case DocumentInvoice:
+		params := &core.InvoiceParams{}
+		if err := params.LoadBytes(data); err != nil {
+			return nil, err
+		}
+		return NewInvoiceBuilder(cfg, params)
+	case DocumentPaymentStatement:
+		params := &core.PaymentStatementParams{}
+		if err := params.LoadBytes(data); err != nil {
+			return nil, err
+		}
+		return NewPaymentStatementBuilder(cfg, params)
+	case DocumentQuote:
+		params := &core.QuoteParams{}
+		if err := params.LoadBytes(data); err != nil {
+			return nil, err
+		}
+		return NewQuoteBuilder(cfg, params)
+	case DocumentReceipt:
+		params := &core.ReceiptParams{}
+		if err := params.LoadBytes(data); err != nil {
+			return nil, err
+		}
+		return NewReceiptBuilder(cfg, params)
+	case DocumentCreditNote:
+		params := &core.CreditNoteParams{}
+		if err := params.LoadBytes(data); err != nil {
+			return nil, err
+		}
+		return NewCreditNoteBuilder(cfg, params)
+	}
+	return nil, fmt.Errorf("unknown document type %q", docType)
+}
+
+// JSONSchema returns the JSON Schema of the params of the given document
+// type. The schemas are committed under schema/ and regenerated with
+// go generate.
+//
+//go:generate go run ../cmd/bizdocgen schema -o ../schema
+func JSONSchema(docType DocumentType) (*core.Schema, error) {
+	switch docType {
+	case DocumentInvoice:
+		return core.JSONSchema(&core.InvoiceParams{}), nil
+	case DocumentPaymentStatement:
+		return core.JSONSchema(&core.PaymentStatementParams{}), nil
+	case DocumentQuote:
+		return core.JSONSchema(&core.QuoteParams{}), nil
+	case DocumentReceipt:
+		return core.JSONSchema(&core.ReceiptParams{}), nil
+	case DocumentCreditNote:
+		return core.JSONSchema(&core.CreditNoteParams{}), nil
+	}
+	return nil, fmt.Errorf("unknown document type %q", docType)
+}
+
+// DocumentType returns the type of document the builder generates.
+func (b *Builder) DocumentType() DocumentType {
+	switch {
+	case b.qParams != nil:
+		return DocumentQuote
+	case b.cnParams != nil:
+		return DocumentCreditNote
+	case b.rParams != nil:
+		return DocumentReceipt
+	case b.psParams != nil:
+		return DocumentPaymentStatement
+	default:
+		return DocumentInvoice
+	}
+}
+
+// Generate renders the document the builder was created for.
+func (b *Builder) Generate() ([]byte, error) {
+	switch b.DocumentType() {
+	case DocumentQuote:
+		return b.GenerateQuote()
+	case DocumentCreditNote:
+		return b.GenerateCreditNote()
+	case DocumentReceipt:
+		return b.GenerateReceipt()
+	case DocumentPaymentStatement:
+		return b.GeneratePaymentStatement()
+	default:
+		return b.GenerateInvoice()
+	}
+}
+
+// GenerateHTML renders the document the builder was created for as HTML.
+// Receipts have no HTML layout.
+func (b *Builder) GenerateHTML() ([]byte, error) {
+	switch b.DocumentType() {
+	case DocumentReceipt:
+		return nil, fmt.Errorf("HTML output is not supported for %s documents", DocumentReceipt)
+	case DocumentPaymentStatement:
+		return b.GeneratePaymentStatementHTML()
+	default:
+		return b.GenerateInvoiceHTML()
+	}
+}
+
+// Info returns the type, ID, date and counterparty of the document.
+func (b *Builder) Info() DocumentInfo {
+	info := DocumentInfo{Type: b.DocumentType()}
+	switch info.Type {
+	case DocumentReceipt:
+		info.ID, info.Date, info.Party = b.rParams.ID, b.rParams.Date, b.rParams.ReceivedFrom
+	case DocumentPaymentStatement:
+		info.ID, info.Date, info.Party = b.psParams.ID, b.psParams.Date, b.psParams.Payee.Name
+	default:
+		info.ID, info.Date, info.Party = b.iParams.ID, b.iParams.Date, b.iParams.BillToCompany
+	}
+	return info
+}
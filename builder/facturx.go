@@ -0,0 +1,146 @@
+package builder
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/quail-ink/bizdocgen/einvoice"
+)
+
+// facturXExtension declares the Factur-X properties in the XMP metadata,
+// with the extension schema PDF/A requires for the fx namespace.
+const facturXExtension = `    <rdf:Description rdf:about="" xmlns:fx="urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#">
+      <fx:DocumentType>INVOICE</fx:DocumentType>
+      <fx:DocumentFileName>` + einvoice.FacturXFilename + `</fx:DocumentFileName>
+      <fx:Version>1.0</fx:Version>
+      <fx:ConformanceLevel>%s</fx:ConformanceLevel>
+    </rdf:Description>
+    <rdf:Description rdf:about=""
+        xmlns:pdfaExtension="http://www.aiim.org/pdfa/ns/extension/"
+        xmlns:pdfaSchema="http://www.aiim.org/pdfa/ns/schema#"
+        xmlns:pdfaProperty="http://www.aiim.org/pdfa/ns/property#">
+      <pdfaExtension:schemas>
+        <rdf:Bag>
+          <rdf:li rdf:parseType="Resource">
+            <pdfaSchema:schema>Factur-X PDFA Extension Schema</pdfaSchema:schema>
+            <pdfaSchema:namespaceURI>urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#</pdfaSchema:namespaceURI>
+            <pdfaSchema:prefix>fx</pdfaSchema:prefix>
+            <pdfaSchema:property>
+              <rdf:Seq>
+                <rdf:li rdf:parseType="Resource">
+                  <pdfaProperty:name>DocumentFileName</pdfaProperty:name>
+                  <pdfaProperty:valueType>Text</pdfaProperty:valueType>
+                  <pdfaProperty:category>external</pdfaProperty:category>
+                  <pdfaProperty:description>name of the embedded XML invoice file</pdfaProperty:description>
+                </rdf:li>
+                <rdf:li rdf:parseType="Resource">
+                  <pdfaProperty:name>DocumentType</pdfaProperty:name>
+                  <pdfaProperty:valueType>Text</pdfaProperty:valueType>
+                  <pdfaProperty:category>external</pdfaProperty:category>
+                  <pdfaProperty:description>INVOICE</pdfaProperty:description>
+                </rdf:li>
+                <rdf:li rdf:parseType="Resource">
+                  <pdfaProperty:name>Version</pdfaProperty:name>
+                  <pdfaProperty:valueType>Text</pdfaProperty:valueType>
+                  <pdfaProperty:category>external</pdfaProperty:category>
+                  <pdfaProperty:description>The actual version of the Factur-X XML schema</pdfaProperty:description>
+                </rdf:li>
+                <rdf:li rdf:parseType="Resource">
+                  <pdfaProperty:name>ConformanceLevel</pdfaProperty:name>
+                  <pdfaProperty:valueType>Text</pdfaProperty:valueType>
+                  <pdfaProperty:category>external</pdfaProperty:category>
+                  <pdfaProperty:description>The conformance level of the embedded Factur-X data</pdfaProperty:description>
+                </rdf:li>
+              </rdf:Seq>
+            </pdfaSchema:property>
+          </rdf:li>
+        </rdf:Bag>
+      </pdfaExtension:schemas>
+    </rdf:Description>`
+
+// embedsFacturX reports whether the builder attaches Factur-X XML to the
+// documents it generates.
+func (b *Builder) embedsFacturX() bool {
+	return b.cfg.FacturX != "" && b.DocumentType() == DocumentInvoice
+}
+
+// checkFacturX refuses params lacking the business terms of the configured
+// Factur-X profile before anything is rendered.
+func (b *Builder) checkFacturX() error {
+	if b.cfg.FacturX == "" {
+		return nil
+	}
+	if b.cfg.PDFA != "" && b.cfg.PDFA.Part() != 3 {
+		log.Printf("Factur-X invoices cannot be PDF/A-%s\n", b.cfg.PDFA)
+		return fmt.Errorf("Factur-X invoices require PDF/A-3, not PDF/A-%s", b.cfg.PDFA)
+	}
+	if err := einvoice.ValidateCII(b.iParams, b.cfg.FacturX).Err(); err != nil {
+		log.Printf("invoice cannot be exported as Factur-X %s: %v\n", b.cfg.FacturX, err)
+		return err
+	}
+	return nil
+}
+
+// embedFacturX attaches the Cross Industry Invoice XML of the params to the
+// document as its alternative representation, producing a Factur-X /
+// ZUGFeRD hybrid invoice once the PDF/A-3 metadata is applied.
+func (b *Builder) embedFacturX(ctx *model.Context) error {
+	// the due date of the PDF, moved off Config.Holidays as well
+	params := *b.iParams
+	params.DueDate = b.dueDate()
+	cii, err := einvoice.MarshalCII(&params, b.cfg.FacturX)
+	if err != nil {
+		return err
+	}
+	xRefTable := ctx.XRefTable
+	root, err := xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+
+	file, err := xRefTable.NewStreamDictForBuf(cii)
+	if err != nil {
+		return err
+	}
+	file.InsertName("Type", "EmbeddedFile")
+	file.InsertName("Subtype", "text#2Fxml")
+	fileParams := types.NewDict()
+	fileParams.InsertInt("Size", len(cii))
+	fileParams.Insert("ModDate", types.StringLiteral(types.DateString(time.Now())))
+	file.Insert("Params", fileParams)
+	if err := file.Encode(); err != nil {
+		return err
+	}
+	fileRef, err := xRefTable.IndRefForNewObject(*file)
+	if err != nil {
+		return err
+	}
+
+	spec, err := xRefTable.NewFileSpecDict(einvoice.FacturXFilename, einvoice.FacturXFilename, "Factur-X invoice", *fileRef)
+	if err != nil {
+		return err
+	}
+	spec.InsertName("AFRelationship", "Alternative")
+	specRef, err := xRefTable.IndRefForNewObject(spec)
+	if err != nil {
+		return err
+	}
+
+	if err := xRefTable.LocateNameTree("EmbeddedFiles", true); err != nil {
+		return err
+	}
+	m := model.NameMap{einvoice.FacturXFilename: []types.Dict{spec}}
+	if err := xRefTable.Names["EmbeddedFiles"].Add(xRefTable, einvoice.FacturXFilename, *specRef, m, []string{"F", "UF"}); err != nil {
+		return err
+	}
+	root.Update("AF", types.Array{*specRef})
+	return nil
+}
+
+// facturXMetadata returns the XMP extension declaring the embedded invoice.
+func (b *Builder) facturXMetadata() string {
+	return fmt.Sprintf(facturXExtension, b.cfg.FacturX.ConformanceLevel())
+}
@@ -0,0 +1,344 @@
+package builder
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+type (
+	htmlDetailItem struct {
+		Date      string
+		Title     string
+		Quantity  string
+		UnitPrice string
+		Amount    string
+		Discount  string
+		Desc      string
+		Tax       string
+		URLs      []string
+	}
+
+	htmlInvoice struct {
+		Lang          string
+		PageTitle     string
+		Title         string
+		Seal          htmlSealImage
+		Logo          htmlLogo
+		Status        string
+		StatusClass   string
+		CompanyName   string
+		CompanyAddr   []string
+		CompanyEmail  string
+		InfoLines     []string
+		BillToLabel   string
+		BillToCompany string
+		BillToAddress string
+		Reason        string
+
+		SummaryTitle string
+		Subtotal     string
+		Breakdown    []summaryLine
+		Tax          string
+		Total        string
+		TotalInWords string
+
+		Itemized    bool
+		Items       []htmlDetailItem
+		ReducedNote bool
+
+		Payment    []summaryLine
+		Notes      template.HTML
+		TermsTitle string
+		Terms      template.HTML
+		Footer     template.HTML
+	}
+
+	htmlSealImage struct {
+		Src   template.URL
+		Style template.CSS
+		// OnName places the seal over the issuer name rather than the
+		// issuer block.
+		OnName bool
+	}
+
+	htmlLogo struct {
+		Src    template.URL
+		Align  string
+		Height float64
+	}
+
+	htmlPsDetailItem struct {
+		Title     string
+		NetAmount string
+		Tax       string
+	}
+
+	htmlPaymentStatement struct {
+		Lang        string
+		Seal        htmlSealImage
+		Logo        htmlLogo
+		Date        string
+		Period      string
+		Payer       core.PaymentStatementPayer
+		Payee       core.PaymentStatementPayee
+		Channel     string
+		TxID        string
+		Revenue     string
+		Withholding string
+		NetAmount   string
+		InWords     string
+		Items       []htmlPsDetailItem
+		Notes       template.HTML
+		Terms       template.HTML
+		Footer      template.HTML
+	}
+)
+
+// newHTMLTemplate parses the named template with a "t" function looking up
+// the i18n strings of the builder language.
+func (b *Builder) newHTMLTemplate(name string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{
+		"t": func(key string) string {
+			return b.i18nBundle.MusT(b.cfg.Lang, key, nil)
+		},
+		"themeCSS": b.theme().css,
+		"sections": b.sections,
+	}).ParseFS(templateFS, "templates/base.html", "templates/"+name)
+}
+
+// htmlLogo returns the company logo of HTML documents.
+func (b *Builder) htmlLogo(ref string) (htmlLogo, error) {
+	src, err := b.imageDataURL(ref)
+	if err != nil {
+		return htmlLogo{}, err
+	}
+	return htmlLogo{Src: src, Align: b.logoAlign(), Height: b.logoHeight()}, nil
+}
+
+func (b *Builder) executeHTML(name string, data any) ([]byte, error) {
+	tmpl, err := b.newHTMLTemplate(name)
+	if err != nil {
+		log.Printf("failed to parse template %s: %v\n", name, err)
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+		log.Printf("failed to render template %s: %v\n", name, err)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateInvoiceHTML renders the invoice, or the quote or credit note the
+// builder was created for, as a self-contained HTML document with the same
+// sections as the PDF.
+func (b *Builder) GenerateInvoiceHTML() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+
+	if b.iParams.QualifiedInvoice {
+		if err := b.iParams.ValidateQualifiedInvoice(); err != nil {
+			log.Printf("invoice is not a valid qualified invoice: %v\n", err)
+			return nil, err
+		}
+	}
+
+	if b.iParams.Summary.Compute {
+		if err := b.iParams.CheckSummary(b.Round); err != nil {
+			log.Printf("invoice summary is inconsistent: %v\n", err)
+			return nil, err
+		}
+	}
+
+	seal, err := b.htmlSeal(b.iParams.CompanySeal, b.iParams.CompanyName)
+	if err != nil {
+		log.Printf("failed to read seal: %v\n", err)
+		return nil, err
+	}
+	logo, err := b.htmlLogo(b.iParams.CompanyLogo)
+	if err != nil {
+		log.Printf("failed to read logo: %v\n", err)
+		return nil, err
+	}
+
+	title, infoLines := b.invoiceHeaderInfo()
+	totals := b.iParams.Totals(b.Round)
+	data := &htmlInvoice{
+		Lang:          b.cfg.Lang,
+		Title:         title,
+		Seal:          seal,
+		Logo:          logo,
+		CompanyName:   b.iParams.CompanyName,
+		CompanyAddr:   strings.Split(b.iParams.CompanyAddr, "\n"),
+		CompanyEmail:  b.iParams.CompanyEmail,
+		InfoLines:     infoLines,
+		BillToLabel:   b.invoiceBillToLabel(),
+		BillToCompany: b.iParams.BillToCompany,
+		BillToAddress: b.iParams.BillToAddress,
+		SummaryTitle:  b.iParams.Summary.Title,
+		Subtotal:      fmt.Sprintf("%s %s", totals.Subtotal.RoundDown(2), b.iParams.Currency),
+		Tax:           fmt.Sprintf("%s %s", totals.Tax, b.iParams.Currency),
+		Total:         fmt.Sprintf("%s %s", totals.Total, b.iParams.Currency),
+	}
+	data.PageTitle = strings.TrimSpace(fmt.Sprintf("%s %s", title, b.iParams.ID))
+	data.TotalInWords = b.amountInWords(totals.Total, b.iParams.Currency)
+	if data.Status, _ = b.invoiceStatus(); data.Status != "" {
+		data.StatusClass = b.iParams.Status
+	}
+	if b.cnParams != nil {
+		data.Reason = b.cnParams.Reason
+	}
+	data.Notes = markupHTML(b.iParams.Notes)
+	data.TermsTitle = b.i18nBundle.MusT(b.cfg.Lang, "Terms", nil)
+	data.Terms = markupHTML(b.iParams.Terms)
+	if b.qParams != nil {
+		data.TermsTitle = b.i18nBundle.MusT(b.cfg.Lang, "QuoteTerms", nil)
+		data.Terms = markupHTML(b.qParams.Terms)
+	}
+	data.Footer = markupHTML(b.footer())
+	// the breakdown is summed from the item amounts
+	if b.iParams.HasItemAmounts() && (b.iParams.QualifiedInvoice || b.iParams.HasMixedTaxRates()) {
+		data.Breakdown = b.invoiceTaxBreakdownLines()
+	}
+
+	for _, item := range b.iParams.DetailItems {
+		if item.IsItemized() {
+			data.Itemized = true
+		}
+	}
+	for _, item := range b.iParams.DetailItems {
+		data.Items = append(data.Items, b.htmlInvoiceItem(item, data.Itemized))
+		if b.iParams.QualifiedInvoice && item.TaxCategory == core.TaxCategoryReduced {
+			data.ReducedNote = true
+		}
+	}
+
+	if !b.iParams.Payment.Disabled {
+		data.Payment = b.invoicePaymentLines()
+	}
+
+	return b.executeHTML("invoice.html", data)
+}
+
+func (b *Builder) htmlInvoiceItem(item core.InvoiceDetailItem, itemized bool) htmlDetailItem {
+	view := htmlDetailItem{
+		Title: item.Title,
+		Desc:  item.Desc,
+		URLs:  item.URLs,
+	}
+	if item.URL != "" {
+		view.URLs = []string{item.URL}
+	}
+	if !item.Date.IsZero() {
+		view.Date = item.Date.Format("2006/01/02")
+	}
+	if b.iParams.QualifiedInvoice && item.TaxCategory == core.TaxCategoryReduced {
+		view.Title = "※ " + view.Title
+	}
+
+	if itemized && item.IsItemized() {
+		view.Quantity = strings.TrimSpace(fmt.Sprintf("%s %s", item.Qty(), item.Unit))
+		view.UnitPrice = item.UnitPrice.StringFixed(b.Round)
+		if !item.Discount.IsZero() {
+			view.Discount = fmt.Sprintf("%s: %s %s", b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsDiscount", nil), item.Discount.Neg().Round(b.Round), b.iParams.Currency)
+		}
+	}
+	view.Amount = b.itemAmountText(item, itemized)
+
+	if item.Desc != "" && !item.LineTotal().IsZero() && !item.Tax.IsZero() {
+		view.Tax = fmt.Sprintf("VAT: %s %s", item.Tax.RoundDown(2), b.iParams.Currency)
+	}
+	return view
+}
+
+// invoicePaymentLines returns the labelled payment instructions that are
+// set in the params.
+func (b *Builder) invoicePaymentLines() []summaryLine {
+	payment := b.iParams.Payment
+	method := payment.Method
+	if method == "" {
+		method = "Bank"
+	}
+	lines := []summaryLine{{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentMethod", nil), method}}
+	for _, line := range []summaryLine{
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentTerms", nil), b.paymentTermsText()},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDueDate", nil), b.dueDateText()},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentID", nil), payment.PaymentID},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankName", nil), payment.ReceiveAccountBank},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankBranch", nil), payment.ReceiveAccountBranch},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankAccount", nil), payment.ReceiveAccountNumber},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankDepositType", nil), payment.ReceiveDepositType},
+		{b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankAccountName", nil), payment.ReceiveAccountName},
+		{"SWIFT", payment.ReceiveAccountSwift},
+		{"Routing Number", payment.ReceiveAccountRouting},
+	} {
+		if line.Amount != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// GeneratePaymentStatementHTML renders the payment statement as a
+// self-contained HTML document with the same sections as the PDF.
+func (b *Builder) GeneratePaymentStatementHTML() ([]byte, error) {
+	if err := b.checkParams(); err != nil {
+		return nil, err
+	}
+
+	seal, err := b.htmlSeal(b.psParams.CompanySeal, b.psParams.Payer.Name)
+	if err != nil {
+		log.Printf("failed to read seal: %v\n", err)
+		return nil, err
+	}
+	logo, err := b.htmlLogo(b.psParams.CompanyLogo)
+	if err != nil {
+		log.Printf("failed to read logo: %v\n", err)
+		return nil, err
+	}
+
+	data := &htmlPaymentStatement{
+		Lang:    b.cfg.Lang,
+		Seal:    seal,
+		Logo:    logo,
+		Date:    b.psParams.Date.Format("2006/01/02"),
+		Period:  fmt.Sprintf("%s - %s", b.psParams.PeriodStart.Format("2006/01/02"), b.psParams.PeriodEnd.Format("2006/01/02")),
+		Payer:   b.psParams.Payer,
+		Payee:   b.psParams.Payee,
+		Channel: b.psParams.PaymentChannel,
+		TxID:    b.psParams.PaymentTxID,
+		Notes:   markupHTML(b.psParams.Notes),
+		Terms:   markupHTML(b.psParams.Terms),
+		Footer:  markupHTML(b.footer()),
+	}
+
+	total := decimal.Zero
+	totalTax := decimal.Zero
+	for _, item := range b.psParams.DetailItems {
+		tax := item.Amount.Mul(item.WithholdingTaxRate)
+		total = total.Add(item.Amount)
+		totalTax = totalTax.Add(tax)
+		data.Items = append(data.Items, htmlPsDetailItem{
+			Title:     item.Title,
+			NetAmount: fmt.Sprintf("%s %s", item.Amount.Sub(tax).Round(b.Round), b.psParams.Currency),
+			Tax:       fmt.Sprintf("%s %s", tax.Round(b.Round), b.psParams.Currency),
+		})
+	}
+	data.Revenue = fmt.Sprintf("%s %s", total.Round(b.Round), b.psParams.Currency)
+	data.Withholding = fmt.Sprintf("-%s %s", totalTax.Round(b.Round), b.psParams.Currency)
+	data.NetAmount = fmt.Sprintf("%s %s", total.Sub(totalTax).Round(b.Round), b.psParams.Currency)
+	data.InWords = b.amountInWords(total.Sub(totalTax), b.psParams.Currency)
+
+	return b.executeHTML("paymentstatement.html", data)
+}
@@ -0,0 +1,263 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"io/fs"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	marotoImage "github.com/johnfercher/maroto/v2/pkg/components/image"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/webp"
+)
+
+type (
+	// LogoPlacement is where the company logo goes in the page header.
+	LogoPlacement struct {
+		// Align puts the logo on the left (the default), in the center or
+		// on the right of the page.
+		Align align.Type
+		// Height is the height of the logo in millimetres, 12 by default.
+		Height float64
+	}
+
+	// docImage is an image of a document, converted to a format maroto can
+	// draw when needed.
+	docImage struct {
+		// mime is the content type of the source image.
+		mime   string
+		source []byte
+		// data is the image drawn into PDFs, of type ext.
+		data []byte
+		ext  extension.Type
+		// width and height are in pixels.
+		width  int
+		height int
+	}
+)
+
+// svgRasterSize is the size in pixels of the longest side of rasterised
+// SVG images, sharp enough for print at the size of logos and seals.
+const svgRasterSize = 1024
+
+// sniffImage returns the content type of the image, "" when the format is
+// not supported.
+func sniffImage(buf []byte) string {
+	switch ct := http.DetectContentType(buf); ct {
+	case "image/png", "image/jpeg", "image/webp":
+		return ct
+	}
+	head := buf
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if bytes.HasPrefix(head, []byte("<")) && bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// decodeImage sniffs the format of the image and converts WebP and SVG
+// images to PNG.
+func decodeImage(buf []byte) (*docImage, error) {
+	img := &docImage{mime: sniffImage(buf), source: buf, data: buf}
+	switch img.mime {
+	case "image/png", "image/jpeg":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		img.ext = extension.Png
+		if img.mime == "image/jpeg" {
+			img.ext = extension.Jpg
+		}
+		img.width, img.height = cfg.Width, cfg.Height
+		return img, nil
+	case "image/webp":
+		decoded, err := webp.Decode(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+		return img, img.setPNG(decoded)
+	case "image/svg+xml":
+		decoded, err := rasteriseSVG(buf)
+		if err != nil {
+			return nil, err
+		}
+		return img, img.setPNG(decoded)
+	default:
+		return nil, fmt.Errorf("unsupported image format %q, expected PNG, JPEG, WebP or SVG", http.DetectContentType(buf))
+	}
+}
+
+// setPNG sets the image drawn into PDFs to decoded, encoded as PNG.
+func (img *docImage) setPNG(decoded image.Image) error {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, decoded); err != nil {
+		return err
+	}
+	img.data = buf.Bytes()
+	img.ext = extension.Png
+	img.width, img.height = decoded.Bounds().Dx(), decoded.Bounds().Dy()
+	return nil
+}
+
+// rasteriseSVG draws the SVG image on a transparent background.
+func rasteriseSVG(buf []byte) (image.Image, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	if icon.ViewBox.W <= 0 || icon.ViewBox.H <= 0 {
+		return nil, fmt.Errorf("SVG image without a size")
+	}
+	scale := svgRasterSize / math.Max(icon.ViewBox.W, icon.ViewBox.H)
+	w, h := int(math.Ceil(icon.ViewBox.W*scale)), int(math.Ceil(icon.ViewBox.H*scale))
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	scanner := rasterx.NewScannerGV(w, h, rgba, rgba.Bounds())
+	icon.Draw(rasterx.NewDasher(w, h, scanner), 1)
+	return rgba, nil
+}
+
+// dataURL returns the source image as a data URL, so HTML documents do not
+// depend on any external file.
+func (img *docImage) dataURL() template.URL {
+	return template.URL(fmt.Sprintf("data:%s;base64,%s", img.mime, base64.StdEncoding.EncodeToString(img.source)))
+}
+
+// component returns the image to be drawn into a PDF column.
+func (img *docImage) component(rect props.Rect) marotoCore.Component {
+	return marotoImage.NewFromBytes(img.data, img.ext, rect)
+}
+
+// readImage returns the image referenced by a CompanySeal or CompanyLogo
+// param: a base64 data URL, a key of Config.Seals when it is set, or a
+// path in Config.Assets or the filesystem.
+func (b *Builder) readImage(ref string) ([]byte, error) {
+	if strings.HasPrefix(ref, "data:") {
+		meta, data, ok := strings.Cut(strings.TrimPrefix(ref, "data:"), ",")
+		if !ok || !strings.HasSuffix(meta, ";base64") {
+			return nil, fmt.Errorf("image data URLs must be base64 encoded")
+		}
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	if b.cfg.Seals != nil {
+		buf, ok := b.cfg.Seals[ref]
+		if !ok {
+			log.Printf("unknown image: %s\n", ref)
+			return nil, fmt.Errorf("unknown image %q", ref)
+		}
+		return buf, nil
+	}
+
+	var (
+		buf []byte
+		err error
+	)
+	if b.cfg.Assets != nil {
+		buf, err = fs.ReadFile(b.cfg.Assets, ref)
+	} else {
+		buf, err = os.ReadFile(ref)
+	}
+	if err != nil {
+		log.Printf("failed to read image file: %v\n", err)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// loadImage reads and decodes the image referenced by a param.
+func (b *Builder) loadImage(ref string) (*docImage, error) {
+	buf, err := b.readImage(ref)
+	if err != nil {
+		return nil, err
+	}
+	img, err := decodeImage(buf)
+	if err != nil {
+		log.Printf("failed to decode image %s: %v\n", ref, err)
+		return nil, err
+	}
+	return img, nil
+}
+
+// imageDataURL returns the image referenced by a param as a data URL, ""
+// when there is none.
+func (b *Builder) imageDataURL(ref string) (template.URL, error) {
+	if ref == "" {
+		return "", nil
+	}
+	img, err := b.loadImage(ref)
+	if err != nil {
+		return "", err
+	}
+	return img.dataURL(), nil
+}
+
+// logoHeight returns the height of the logo in millimetres.
+func (b *Builder) logoHeight() float64 {
+	if b.cfg.Logo.Height > 0 {
+		return b.cfg.Logo.Height
+	}
+	return 12
+}
+
+// BuildLogoRows returns the rows of the company logo above the page header,
+// none when ref is empty.
+func (b *Builder) BuildLogoRows(ref string) ([]marotoCore.Row, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	logo, err := b.loadImage(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	height := b.logoHeight()
+	rect := props.Rect{Percent: 100}
+	switch b.cfg.Logo.Align {
+	case align.Center:
+		rect.Center = true
+	case align.Right:
+		// the logo fills the height of the row unless it is wider than the
+		// page, push it to the right by the space left beside it
+		width := b.measurer().width
+		if logoWidth := height * float64(logo.width) / float64(logo.height); logoWidth < width {
+			rect.Left = width - logoWidth
+		}
+	}
+	return []marotoCore.Row{
+		row.New(height).Add(col.New(12).Add(logo.component(rect))),
+		row.New(2),
+	}, nil
+}
+
+// logoAlign returns the CSS text-align of the logo in HTML documents.
+func (b *Builder) logoAlign() string {
+	switch b.cfg.Logo.Align {
+	case align.Center:
+		return "center"
+	case align.Right:
+		return "right"
+	default:
+		return "left"
+	}
+}
@@ -2,98 +2,232 @@ package builder
 
 import (
 	"fmt"
-	"io"
-	"log"
-	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
-	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
 	"github.com/johnfercher/maroto/v2/pkg/consts/border"
-	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quail-ink/bizdocgen/core"
 	"github.com/shopspring/decimal"
 )
 
-func (b *Builder) BuildInvoiceHeader() ([]marotoCore.Row, error) {
+// invoiceHeaderInfo returns the document title, empty for plain invoices,
+// and the ID, tax number and date lines shown next to the issuer.
+func (b *Builder) invoiceHeaderInfo() (string, []string) {
 	tInvoiceID := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceID", nil)
 	tTaxID := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceTaxID", nil)
+	if b.iParams.QualifiedInvoice {
+		tTaxID = b.i18nBundle.MusT(b.cfg.Lang, "InvoiceRegistrationNumber", nil)
+	}
 	tIssueDate := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceIssueDate", nil)
 	tPeriod := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePeriod", nil)
+	tTitle := ""
+	if b.qParams != nil {
+		tTitle = b.i18nBundle.MusT(b.cfg.Lang, "QuoteTitle", nil)
+		tInvoiceID = b.i18nBundle.MusT(b.cfg.Lang, "QuoteID", nil)
+		tIssueDate = b.i18nBundle.MusT(b.cfg.Lang, "QuoteIssueDate", nil)
+	} else if b.cnParams != nil {
+		tTitle = b.i18nBundle.MusT(b.cfg.Lang, "CreditNoteTitle", nil)
+		tInvoiceID = b.i18nBundle.MusT(b.cfg.Lang, "CreditNoteID", nil)
+		tIssueDate = b.i18nBundle.MusT(b.cfg.Lang, "CreditNoteIssueDate", nil)
+	}
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
+	infoLines := []string{
+		fmt.Sprintf("%s: %s", tInvoiceID, b.iParams.ID),
+		fmt.Sprintf("%s: %s", tTaxID, b.iParams.TaxNumber),
+		fmt.Sprintf("%s: %s", tIssueDate, b.iParams.Date.Format("2006/01/02")),
 	}
-	leftCol := col.New(6)
+	if due := b.dueDateText(); due != "" {
+		tDueDate := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDueDate", nil)
+		infoLines = append(infoLines, fmt.Sprintf("%s: %s", tDueDate, due))
+	}
+	if b.qParams != nil {
+		tValidUntil := b.i18nBundle.MusT(b.cfg.Lang, "QuoteValidUntil", nil)
+		infoLines = append(infoLines, fmt.Sprintf("%s: %s", tValidUntil, b.qParams.ValidUntil.Format("2006/01/02")))
+	} else if b.cnParams != nil {
+		tOriginal := b.i18nBundle.MusT(b.cfg.Lang, "CreditNoteOriginalInvoice", nil)
+		infoLines = append(infoLines, fmt.Sprintf("%s: %s (%s)", tOriginal,
+			b.cnParams.OriginalInvoiceID,
+			b.cnParams.OriginalInvoiceDate.Format("2006/01/02"),
+		))
+	} else {
+		infoLines = append(infoLines, fmt.Sprintf("%s: %s - %s", tPeriod,
+			b.iParams.Summary.PeriodStart.Format("2006/01/02"),
+			b.iParams.Summary.PeriodEnd.Format("2006/01/02"),
+		))
+	}
+	return tTitle, infoLines
+}
 
-	if b.iParams.CompanySeal != "" {
-		fd, err := os.Open(b.iParams.CompanySeal)
-		if err != nil {
-			log.Printf("failed to open seal file: %v\n", err)
-			return nil, err
-		}
-		defer fd.Close()
-		buf, err := io.ReadAll(fd)
-		if err != nil {
-			log.Printf("failed to read seal file: %v\n", err)
-			return nil, err
+// dueDate returns the due date of invoices, given or computed from the
+// payment terms, or the zero time.
+func (b *Builder) dueDate() time.Time {
+	if b.qParams != nil || b.cnParams != nil {
+		return time.Time{}
+	}
+	return b.iParams.ComputeDueDate(b.cfg.Holidays)
+}
+
+// dueDateText returns the formatted due date of invoices, "" when unknown.
+func (b *Builder) dueDateText() string {
+	if due := b.dueDate(); !due.IsZero() {
+		return due.Format("2006/01/02")
+	}
+	return ""
+}
+
+// paymentTermsText returns the payment terms of invoices in the document
+// language, as given when they cannot be parsed.
+func (b *Builder) paymentTermsText() string {
+	if b.qParams != nil || b.cnParams != nil || b.iParams.PaymentTerms == "" {
+		return ""
+	}
+	terms, err := core.ParsePaymentTerms(b.iParams.PaymentTerms)
+	if err != nil {
+		return b.iParams.PaymentTerms
+	}
+	if terms.Net {
+		if terms.Days == 0 {
+			return b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsOnReceipt", nil)
 		}
+		return b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsNet", map[string]string{"Days": strconv.Itoa(terms.Days)})
+	}
 
-		leftCol.Add(image.NewFromBytes(buf, extension.Png, props.Rect{
-			Center:  false,
-			Percent: 20,
-			Left:    34,
-			Top:     7,
-		}))
+	var month string
+	switch terms.Months {
+	case 0:
+		month = b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsThisMonth", nil)
+	case 1:
+		month = b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsNextMonth", nil)
+	default:
+		month = b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsMonthsLater", map[string]string{"Months": strconv.Itoa(terms.Months)})
 	}
+	if terms.Day == 0 {
+		return b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsEndOfMonth", map[string]string{"Month": month})
+	}
+	return b.i18nBundle.MusT(b.cfg.Lang, "PaymentTermsDayOfMonth", map[string]string{"Month": month, "Day": strconv.Itoa(terms.Day)})
+}
+
+// invoiceStatus returns the label and colour of the status stamp of
+// invoices, an empty label when there is none.
+func (b *Builder) invoiceStatus() (string, props.Color) {
+	if b.qParams != nil || b.cnParams != nil {
+		return "", props.Color{}
+	}
+	switch b.iParams.Status {
+	case core.InvoiceStatusPaid:
+		return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceStatusPaid", nil), b.theme().Palette.Paid
+	case core.InvoiceStatusOverdue:
+		return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceStatusOverdue", nil), b.theme().Palette.Overdue
+	default:
+		return "", props.Color{}
+	}
+}
 
-	leftCol.Add(text.New(b.iParams.CompanyName, props.Text{Size: 14, Top: 8, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}))
+// BuildInvoiceStatusRows returns the rows of the "PAID" or "OVERDUE" stamp
+// below the page header, none for invoices without a status.
+func (b *Builder) BuildInvoiceStatusRows() []marotoCore.Row {
+	label, color := b.invoiceStatus()
+	if label == "" {
+		return nil
+	}
+	stampStyle := &props.Cell{
+		BorderType:      border.Full,
+		BorderColor:     &color,
+		BorderThickness: 0.8,
+	}
+	return []marotoCore.Row{
+		row.New(4),
+		row.New(12).Add(
+			col.New(8),
+			col.New(4).WithStyle(stampStyle).Add(
+				text.New(label, props.Text{Size: b.theme().Typography.Headline, Top: 2.5, Align: align.Center, Style: fontstyle.Bold, Color: &color}),
+			),
+		),
+	}
+}
+
+// invoiceBillToLabel returns the heading of the recipient section.
+func (b *Builder) invoiceBillToLabel() string {
+	if b.qParams != nil {
+		return b.i18nBundle.MusT(b.cfg.Lang, "QuoteTo", nil)
+	}
+	return b.i18nBundle.MusT(b.cfg.Lang, "InvoiceBillTo", nil)
+}
+
+func (b *Builder) BuildInvoiceHeader() ([]marotoCore.Row, error) {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
+	tTitle, infoLines := b.invoiceHeaderInfo()
+
+	borderBottomStyle := b.borderStyle()
+	leftCol := col.New(6)
+
+	leftCol.Add(text.New(b.iParams.CompanyName, props.Text{Size: sizes.Headline, Top: 8, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}))
 	lines := strings.Split(b.iParams.CompanyAddr, "\n")
 	for ix, line := range lines {
-		leftCol.Add(text.New(line, props.Text{Size: 9, Top: float64(6*ix + 16), Align: align.Left, Color: b.fgColor}))
+		leftCol.Add(text.New(line, props.Text{Size: sizes.Body, Top: float64(6*ix + 16), Align: align.Left, Color: b.fgColor}))
 	}
-	leftCol.Add(text.New(b.iParams.CompanyEmail, props.Text{Size: 9, Top: float64(6*(len(lines)) + 16), Align: align.Left, Color: b.fgColor}))
+	leftCol.Add(text.New(b.iParams.CompanyEmail, props.Text{Size: sizes.Body, Top: float64(6*(len(lines)) + 16), Align: align.Left, Color: b.fgColor}))
 
-	rs := row.New(42).WithStyle(borderBottomStyle).Add(
+	// the info lines start at 16mm, 6mm apart
+	headerHeight := max(42, float64(6*len(infoLines)+14))
+	seal, err := b.sealComponent(b.iParams.CompanySeal, b.iParams.CompanyName, 6, headerHeight, 8, sizes.Headline)
+	if err != nil {
+		return nil, err
+	}
+	if seal != nil {
+		leftCol.Add(seal)
+	}
+
+	rightCol := col.New(6)
+	if tTitle != "" {
+		rightCol.Add(text.New(tTitle, props.Text{Size: sizes.Headline, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}))
+	}
+	for ix, line := range infoLines {
+		rightCol.Add(text.New(line, props.Text{Size: sizes.Body, Top: float64(6*ix + 16), Align: align.Right, Color: b.fgColor}))
+	}
+
+	rs := row.New(headerHeight).WithStyle(borderBottomStyle).Add(
 		leftCol,
-		col.New(6).Add(
-			text.New(fmt.Sprintf("%s: %s", tInvoiceID, b.iParams.ID), props.Text{Size: 9, Top: 16, Align: align.Right, Color: b.fgColor}),
-			text.New(fmt.Sprintf("%s: %s", tTaxID, b.iParams.TaxNumber), props.Text{Size: 9, Top: 22, Align: align.Right, Color: b.fgColor}),
-			text.New(fmt.Sprintf("%s: %s", tIssueDate, b.iParams.Date.Format("2006/01/02")), props.Text{Size: 9, Top: 28, Align: align.Right, Color: b.fgColor}),
-			text.New(fmt.Sprintf("%s: %s - %s", tPeriod,
-				b.iParams.Summary.PeriodStart.Format("2006/01/02"),
-				b.iParams.Summary.PeriodEnd.Format("2006/01/02"),
-			), props.Text{Size: 9, Top: 34, Align: align.Right, Color: b.fgColor}),
-		),
+		rightCol,
 	)
 
-	rows := []marotoCore.Row{
-		rs,
-		row.New(6),
+	rows := []marotoCore.Row{rs}
+	rows = append(rows, b.BuildInvoiceStatusRows()...)
+	rows = append(rows, row.New(spacing.Line))
+
+	logo, err := b.BuildLogoRows(b.iParams.CompanyLogo)
+	if err != nil {
+		return nil, err
 	}
-	return rows, nil
+	return append(logo, rows...), nil
 }
 
 func (b *Builder) BuildInvoiceBillTo() []marotoCore.Row {
-	tBillTo := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceBillTo", nil)
+	sizes := b.theme().Typography
+	tBillTo := b.invoiceBillToLabel()
 
 	billTo := col.New(8)
-	billTo.Add(text.New(b.iParams.BillToCompany, props.Text{Size: 9, Top: float64(0), Style: fontstyle.Bold, Color: b.fgColor}))
-	billTo.Add(text.New(b.iParams.BillToAddress, props.Text{Size: 9, Top: float64(6), Color: b.fgColor}))
+	billTo.Add(text.New(b.iParams.BillToCompany, props.Text{Size: sizes.Body, Top: float64(0), Style: fontstyle.Bold, Color: b.fgColor}))
+	billTo.Add(text.New(b.iParams.BillToAddress, props.Text{Size: sizes.Body, Top: float64(6), Color: b.fgColor}))
 
 	return []marotoCore.Row{
-		text.NewRow(8, tBillTo, props.Text{Size: 10, Top: 0, Style: fontstyle.Bold, Color: b.fgColor}),
+		text.NewRow(8, tBillTo, props.Text{Size: sizes.Subheading, Top: 0, Style: fontstyle.Bold, Color: b.fgColor}),
 		row.New(12).Add(billTo),
 	}
 }
 
 func (b *Builder) BuildInvoicePaymentRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tPayment := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePayment", nil)
 	tMethod := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentMethod", nil)
 	tPaymentID := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentID", nil)
@@ -102,16 +236,15 @@ func (b *Builder) BuildInvoicePaymentRows() []marotoCore.Row {
 	tBankDepositType := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankDepositType", nil)
 	tBankAccount := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankAccount", nil)
 	tBankAccountName := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentBankAccountName", nil)
+	tTerms := b.i18nBundle.MusT(b.cfg.Lang, "InvoicePaymentTerms", nil)
+	tDueDate := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDueDate", nil)
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
-	}
+	borderBottomStyle := b.borderStyle()
 
 	rows := []marotoCore.Row{
-		row.New(16).WithStyle(borderBottomStyle).Add(
-			text.NewCol(8, tPayment, props.Text{Size: 10, Top: 8, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
-			text.NewCol(4, "", props.Text{Size: 10, Top: 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+		row.New(b.headingHeight()).WithStyle(borderBottomStyle).Add(
+			text.NewCol(8, tPayment, props.Text{Size: sizes.Subheading, Top: spacing.Section, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(4, "", props.Text{Size: sizes.Subheading, Top: spacing.Section, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
 	}
 
@@ -120,97 +253,119 @@ func (b *Builder) BuildInvoicePaymentRows() []marotoCore.Row {
 	}
 	rows = append(rows, row.New(10).Add(
 		col.New(2).Add(
-			text.New(tMethod, props.Text{Size: 9, Top: 4, Align: align.Left, Color: b.fgColor}),
+			text.New(tMethod, props.Text{Size: sizes.Body, Top: 4, Align: align.Left, Color: b.fgColor}),
 		),
 		col.New(10).Add(
-			text.New(b.iParams.Payment.Method, props.Text{Size: 9, Top: 4, Align: align.Right, Color: b.fgColor}),
+			text.New(b.iParams.Payment.Method, props.Text{Size: sizes.Body, Top: 4, Align: align.Right, Color: b.fgColor}),
 		),
 	))
 
+	if terms := b.paymentTermsText(); terms != "" {
+		rows = append(rows, row.New(spacing.Line).Add(
+			col.New(2).Add(
+				text.New(tTerms, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
+			),
+			col.New(10).Add(
+				text.New(terms, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
+			),
+		))
+	}
+
+	if due := b.dueDateText(); due != "" {
+		rows = append(rows, row.New(spacing.Line).Add(
+			col.New(2).Add(
+				text.New(tDueDate, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
+			),
+			col.New(10).Add(
+				text.New(due, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+			),
+		))
+	}
+
 	if b.iParams.Payment.PaymentID != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New(tPaymentID, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New(tPaymentID, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.PaymentID, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.PaymentID, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveAccountBank != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New(tBankName, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New(tBankName, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveAccountBank, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveAccountBank, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveAccountBranch != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New(tBankBranch, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New(tBankBranch, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveAccountBranch, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveAccountBranch, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveAccountNumber != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New(tBankAccount, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New(tBankAccount, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveAccountNumber, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveAccountNumber, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveDepositType != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New(tBankDepositType, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New(tBankDepositType, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveDepositType, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveDepositType, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveAccountName != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New(tBankAccountName, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New(tBankAccountName, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveAccountName, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveAccountName, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveAccountSwift != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New("SWIFT", props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New("SWIFT", props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveAccountSwift, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveAccountSwift, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
 
 	if b.iParams.Payment.ReceiveAccountRouting != "" {
-		rows = append(rows, row.New(6).Add(
+		rows = append(rows, row.New(spacing.Line).Add(
 			col.New(2).Add(
-				text.New("Routing Number", props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
+				text.New("Routing Number", props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
 			),
 			col.New(10).Add(
-				text.New(b.iParams.Payment.ReceiveAccountRouting, props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+				text.New(b.iParams.Payment.ReceiveAccountRouting, props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 			),
 		))
 	}
@@ -218,149 +373,284 @@ func (b *Builder) BuildInvoicePaymentRows() []marotoCore.Row {
 }
 
 func (b *Builder) BuildInvoiceDetailsRows() []marotoCore.Row {
+	return b.invoiceDetailsTable().rows()
+}
+
+// invoiceDetailsTable builds the detail items as a table, keeping the rows
+// of each item together.
+func (b *Builder) invoiceDetailsTable() *table {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tDetails := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetails", nil)
 
-	colorLink := &props.Color{
-		Red:   0,
-		Green: 0,
-		Blue:  255,
-	}
+	colorLink := &b.theme().Palette.Link
+
+	borderBottomStyle := b.borderStyle()
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
+	itemized := false
+	for _, item := range b.iParams.DetailItems {
+		if item.IsItemized() {
+			itemized = true
+			break
+		}
 	}
 
-	rows := []marotoCore.Row{
-		row.New(16).WithStyle(borderBottomStyle).Add(
-			text.NewCol(8, tDetails, props.Text{Size: 10, Top: 8, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
-			text.NewCol(4, "", props.Text{Size: 10, Top: 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
-		),
+	t := &table{}
+	t.header = func(continued bool) []marotoCore.Row {
+		title := tDetails
+		if continued {
+			title = b.i18nBundle.MusT(b.cfg.Lang, "Continued", map[string]string{"Title": tDetails})
+		}
+		rows := []marotoCore.Row{
+			row.New(b.headingHeight()).WithStyle(borderBottomStyle).Add(
+				text.NewCol(8, title, props.Text{Size: sizes.Subheading, Top: spacing.Section, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+				text.NewCol(4, "", props.Text{Size: sizes.Subheading, Top: spacing.Section, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+			),
+		}
+
+		if itemized {
+			tQuantity := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsQuantity", nil)
+			tUnitPrice := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsUnitPrice", nil)
+			tAmount := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsAmount", nil)
+			rows = append(rows, row.New(10).WithStyle(borderBottomStyle).Add(
+				col.New(6),
+				text.NewCol(2, tQuantity, props.Text{Size: sizes.Caption, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgSecondaryColor}),
+				text.NewCol(2, tUnitPrice, props.Text{Size: sizes.Caption, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgSecondaryColor}),
+				text.NewCol(2, tAmount, props.Text{Size: sizes.Caption, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgSecondaryColor}),
+			))
+		}
+		return rows
 	}
 
+	hasReducedItems := false
 	for ix, item := range b.iParams.DetailItems {
+		title := item.Title
+		date := ""
+		if !item.Date.IsZero() {
+			date = item.Date.Format("2006/01/02")
+		}
+		if b.iParams.QualifiedInvoice && item.TaxCategory == core.TaxCategoryReduced {
+			title = "※ " + title
+			hasReducedItems = true
+		}
 		paddingTop := float64(0)
-		rowHeight := float64(6)
+		rowHeight := spacing.Line
 		if ix == 0 {
 			paddingTop = float64(4)
 			rowHeight = float64(10)
 		}
 		r := row.New(rowHeight)
-		r.Add(
-			col.New(2).Add(
-				text.New(item.Date.Format("2006/01/02"), props.Text{Size: 9, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
-			),
-			col.New(6).Add(
-				text.New(item.Title, props.Text{Size: 9, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
-			),
-		)
-		if item.TotalExcludeTax.IsPositive() || item.TotalIncludeTax.IsPositive() {
-			if item.TotalIncludeTax.IsPositive() {
+		if itemized {
+			r.Add(
+				col.New(2).Add(
+					text.New(date, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
+				),
+				col.New(4).Add(
+					text.New(title, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
+				),
+			)
+			if item.IsItemized() {
+				qty := strings.TrimSpace(fmt.Sprintf("%s %s", item.Qty(), item.Unit))
 				r.Add(
-					col.New(4).Add(
-						text.New(fmt.Sprintf("%s %s", item.TotalIncludeTax.RoundDown(2), b.iParams.Currency), props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+					col.New(2).Add(
+						text.New(qty, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+					),
+					col.New(2).Add(
+						text.New(item.UnitPrice.StringFixed(b.Round), props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
 					),
 				)
 			} else {
+				r.Add(col.New(4))
+			}
+			if amount := b.itemAmountText(item, itemized); amount != "" {
+				r.Add(
+					col.New(2).Add(
+						text.New(amount, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+					),
+				)
+			}
+		} else {
+			r.Add(
+				col.New(2).Add(
+					text.New(date, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
+				),
+				col.New(6).Add(
+					text.New(title, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Left, Color: b.fgColor}),
+				),
+			)
+			if amount := b.itemAmountText(item, itemized); amount != "" {
 				r.Add(
 					col.New(4).Add(
-						text.New(fmt.Sprintf("%s %s", item.TotalExcludeTax.RoundDown(2), b.iParams.Currency), props.Text{Size: 9, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
+						text.New(amount, props.Text{Size: sizes.Body, Top: paddingTop, Align: align.Right, Color: b.fgColor}),
 					),
 				)
 			}
 		}
-		rows = append(rows, r)
+		group := []marotoCore.Row{r}
+
+		if item.IsItemized() && !item.Discount.IsZero() {
+			tDiscount := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceDetailsDiscount", nil)
+			group = append(group, row.New(spacing.Line).Add(
+				col.New(2),
+				col.New(6),
+				col.New(4).Add(
+					text.New(fmt.Sprintf("%s: %s %s", tDiscount, item.Discount.Neg().Round(b.Round), b.iParams.Currency), props.Text{Size: sizes.Caption, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+				),
+			))
+		}
 
 		if item.Desc != "" {
-			r := row.New(6)
+			r := row.New(spacing.Line)
 			r.Add(
 				col.New(2),
 			)
-			if item.TotalExcludeTax.IsPositive() && item.Tax.IsPositive() {
+			if !item.LineTotal().IsZero() && !item.Tax.IsZero() {
 				r.Add(
 					col.New(6).Add(
-						text.New(item.Desc, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+						text.New(item.Desc, props.Text{Size: sizes.Caption, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
 					),
 					col.New(4).Add(
-						text.New(fmt.Sprintf("VAT: %s %s", item.Tax.RoundDown(2), b.iParams.Currency), props.Text{Size: 8, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+						text.New(fmt.Sprintf("VAT: %s %s", item.Tax.RoundDown(2), b.iParams.Currency), props.Text{Size: sizes.Caption, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
 					),
 				)
 			} else {
 				r.Add(
 					col.New(10).Add(
-						text.New(item.Desc, props.Text{Size: 8, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+						text.New(item.Desc, props.Text{Size: sizes.Caption, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
 					),
 				)
 			}
-			rows = append(rows, r)
+			group = append(group, r)
 		}
 		if item.URL != "" {
 			url := item.URL
-			rows = append(rows, row.New(6).Add(
+			group = append(group, row.New(spacing.Line).Add(
 				col.New(2),
 				col.New(10).Add(
-					text.New(item.URL, props.Text{Size: 8, Top: 0, Align: align.Left, Hyperlink: &url, Color: colorLink}),
+					text.New(item.URL, props.Text{Size: sizes.Caption, Top: 0, Align: align.Left, Hyperlink: &url, Color: colorLink}),
 				),
 			))
 		} else if len(item.URLs) > 0 {
 			for _, url := range item.URLs {
-				rows = append(rows, row.New(6).Add(
+				group = append(group, row.New(spacing.Line).Add(
 					col.New(2),
 					col.New(10).Add(
-						text.New(url, props.Text{Size: 8, Top: 0, Align: align.Left, Hyperlink: &url, Color: colorLink}),
+						text.New(url, props.Text{Size: sizes.Caption, Top: 0, Align: align.Left, Hyperlink: &url, Color: colorLink}),
 					),
 				))
 			}
 		}
-		rows = append(rows, row.New(2))
+		t.groups = append(t.groups, append(group, row.New(spacing.Item)))
 	}
-	return rows
+
+	if hasReducedItems {
+		tReducedNote := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceReducedRateNote", nil)
+		t.footer = append(t.footer, row.New(8).Add(
+			text.NewCol(12, tReducedNote, props.Text{Size: sizes.Caption, Top: 2, Align: align.Left, Color: b.fgSecondaryColor}),
+		))
+	}
+	return t
 }
 
 func (b *Builder) BuildInvoiceSummaryRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tSummary := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummary", nil)
 	tAmount := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryAmount", nil)
 	tVAT := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryVAT", nil)
 	tTotal := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryTotalWithTax", nil)
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
-	}
+	borderBottomStyle := b.borderStyle()
 
-	var total, tax, subtotal decimal.Decimal
-	if b.iParams.Summary.TotalExcludeTax.IsPositive() {
-		// tax excluded?
-		subtotal = b.iParams.Summary.TotalExcludeTax
-		if b.iParams.Summary.Tax.IsPositive() {
-			tax = b.iParams.Summary.Tax.Round(2)
-		} else if b.iParams.Summary.TaxRate.IsPositive() {
-			tax = subtotal.Mul(b.iParams.Summary.TaxRate).Round(2)
-		}
-		total = subtotal.Add(tax).Round(2)
-	} else {
-		// tax included?
-		total = b.iParams.Summary.TotalIncludeTax
-		subtotal = total.Div(decimal.NewFromFloat(1).Add(b.iParams.Summary.TaxRate)).Round(2)
-		tax = total.Sub(subtotal).Round(2)
-	}
+	totals := b.iParams.Totals(b.Round)
+	subtotal, tax, total := totals.Subtotal, totals.Tax, totals.Total
 
-	return []marotoCore.Row{
-		row.New(16).WithStyle(borderBottomStyle).Add(
-			text.NewCol(8, tSummary, props.Text{Size: 10, Top: 8, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
-			text.NewCol(4, tAmount, props.Text{Size: 10, Top: 8, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+	rows := []marotoCore.Row{
+		row.New(b.headingHeight()).WithStyle(borderBottomStyle).Add(
+			text.NewCol(8, tSummary, props.Text{Size: sizes.Subheading, Top: spacing.Section, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(4, tAmount, props.Text{Size: sizes.Subheading, Top: spacing.Section, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
 		row.New(12).Add(
-			text.NewCol(8, b.iParams.Summary.Title, props.Text{Size: 9, Top: 4, Align: align.Left, Color: b.fgColor}),
-			text.NewCol(4, fmt.Sprintf("%s %s", subtotal.RoundDown(2), b.iParams.Currency), props.Text{Size: 9, Top: 4, Align: align.Right, Color: b.fgColor}),
+			text.NewCol(8, b.iParams.Summary.Title, props.Text{Size: sizes.Body, Top: 4, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(4, fmt.Sprintf("%s %s", subtotal.RoundDown(2), b.iParams.Currency), props.Text{Size: sizes.Body, Top: 4, Align: align.Right, Color: b.fgColor}),
 		),
+	}
+
+	// the breakdown is summed from the item amounts
+	if b.iParams.HasItemAmounts() && (b.iParams.QualifiedInvoice || b.iParams.HasMixedTaxRates()) {
+		rows = append(rows, b.buildInvoiceTaxBreakdownRows()...)
+	}
+
+	rows = append(rows,
 		row.New(8).WithStyle(borderBottomStyle).Add(
-			text.NewCol(6, tVAT, props.Text{Size: 9, Top: 0, Align: align.Left, Color: b.fgColor}),
-			text.NewCol(6, fmt.Sprintf("%s %s", tax, b.iParams.Currency), props.Text{Size: 9, Top: 0, Align: align.Right, Color: b.fgColor}),
+			text.NewCol(6, tVAT, props.Text{Size: sizes.Body, Top: 0, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(6, fmt.Sprintf("%s %s", tax, b.iParams.Currency), props.Text{Size: sizes.Body, Top: 0, Align: align.Right, Color: b.fgColor}),
 		),
 		row.New(10).Add(
-			text.NewCol(6, tTotal, props.Text{Size: 10, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
-			text.NewCol(6, fmt.Sprintf("%s %s", total, b.iParams.Currency), props.Text{Size: 10, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(6, tTotal, props.Text{Size: sizes.Subheading, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+			text.NewCol(6, fmt.Sprintf("%s %s", total, b.iParams.Currency), props.Text{Size: sizes.Subheading, Top: 4, Align: align.Right, Style: fontstyle.Bold, Color: b.fgColor}),
 		),
+	)
+	return append(rows, b.BuildAmountInWordsRows(total, b.iParams.Currency, align.Right)...)
+}
+
+// itemAmountText returns the amount shown for the detail item in the PDF
+// and HTML details tables, rounded to the currency. Tables with itemized
+// items show every item excluding tax, like their line totals, and other
+// tables the total including tax when items set it. Items without any
+// amount show "".
+func (b *Builder) itemAmountText(item core.InvoiceDetailItem, itemized bool) string {
+	amount := item.TotalExcludeTax
+	switch {
+	case itemized:
+		amount, _ = b.iParams.ItemAmounts(&item)
+	case !item.TotalIncludeTax.IsZero():
+		amount = item.TotalIncludeTax
+	}
+	if amount.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", amount.Round(b.Round), b.iParams.Currency)
+}
+
+type summaryLine struct {
+	Label  string
+	Amount string
+}
+
+// invoiceTaxBreakdownLines returns the subtotal and tax of every tax
+// category and rate found in the detail items.
+func (b *Builder) invoiceTaxBreakdownLines() []summaryLine {
+	lines := []summaryLine{}
+	for _, group := range b.iParams.TaxBreakdown(b.Round) {
+		data := map[string]string{
+			"Rate": group.Rate.Mul(decimal.NewFromInt(100)).String() + "%",
+		}
+		switch group.Category {
+		case core.TaxCategoryZero:
+			lines = append(lines, summaryLine{b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryZeroRatedSubtotal", data), fmt.Sprintf("%s %s", group.Subtotal, b.iParams.Currency)})
+		case core.TaxCategoryExempt:
+			lines = append(lines, summaryLine{b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryExemptSubtotal", data), fmt.Sprintf("%s %s", group.Subtotal, b.iParams.Currency)})
+		default:
+			lines = append(lines,
+				summaryLine{b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryRateSubtotal", data), fmt.Sprintf("%s %s", group.Subtotal, b.iParams.Currency)},
+				summaryLine{b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryRateVAT", data), fmt.Sprintf("%s %s", group.Tax, b.iParams.Currency)},
+			)
+		}
+	}
+	return lines
+}
+
+// buildInvoiceTaxBreakdownRows renders the tax breakdown lines.
+func (b *Builder) buildInvoiceTaxBreakdownRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
+	rows := []marotoCore.Row{}
+	for _, line := range b.invoiceTaxBreakdownLines() {
+		rows = append(rows, row.New(spacing.Line).Add(
+			text.NewCol(6, line.Label, props.Text{Size: sizes.Caption, Top: 0, Align: align.Left, Color: b.fgSecondaryColor}),
+			text.NewCol(6, line.Amount, props.Text{Size: sizes.Caption, Top: 0, Align: align.Right, Color: b.fgSecondaryColor}),
+		))
 	}
+	return append(rows, row.New(spacing.Item))
 }
@@ -0,0 +1,174 @@
+package builder
+
+import (
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+type (
+	// layout is the body of a document, below the page header repeated by
+	// maroto. It is split into pages before rendering so that kept rows
+	// never break across pages and tables repeat their column headers.
+	layout struct {
+		blocks []layoutBlock
+	}
+
+	// layoutBlock is either rows kept together or a table.
+	layoutBlock struct {
+		rows  []marotoCore.Row
+		table *table
+	}
+
+	// table is a section of rows, such as the detail items, whose groups
+	// are each kept on one page.
+	table struct {
+		// header returns the title and column header rows of the table,
+		// marked as continued on the pages after the first.
+		header func(continued bool) []marotoCore.Row
+		groups [][]marotoCore.Row
+		// footer rows follow the last group, such as notes on the items.
+		footer []marotoCore.Row
+	}
+)
+
+// keep appends rows that are moved to the next page together when they do
+// not fit on the current one.
+func (l *layout) keep(rows ...marotoCore.Row) {
+	l.blocks = append(l.blocks, layoutBlock{rows: rows})
+}
+
+// keepEach appends groups of rows, each kept together.
+func (l *layout) keepEach(groups [][]marotoCore.Row) {
+	for _, rows := range groups {
+		l.keep(rows...)
+	}
+}
+
+func (l *layout) table(t *table) {
+	l.blocks = append(l.blocks, layoutBlock{table: t})
+}
+
+func (t *table) rows() []marotoCore.Row {
+	rows := t.header(false)
+	for _, group := range t.groups {
+		rows = append(rows, group...)
+	}
+	return append(rows, t.footer...)
+}
+
+// paginator splits rows into pages the way maroto fills them: a row fits
+// when the page height used so far plus its own stays below the useful
+// height of the page, and every page starts below the page header.
+type paginator struct {
+	height float64
+	top    float64
+	used   float64
+	pages  [][]marotoCore.Row
+	// onBreak is called after a page break, to repeat table headers.
+	onBreak func()
+}
+
+func (p *paginator) newPage() {
+	p.pages = append(p.pages, []marotoCore.Row{})
+	p.used = p.top
+	if p.onBreak != nil {
+		p.onBreak()
+	}
+}
+
+func (p *paginator) empty() bool {
+	return p.used == p.top
+}
+
+func (p *paginator) fits(height float64) bool {
+	return p.used+height < p.height
+}
+
+func (p *paginator) append(rows ...marotoCore.Row) {
+	for _, r := range rows {
+		if !p.fits(r.GetHeight()) && !p.empty() {
+			p.newPage()
+		}
+		last := len(p.pages) - 1
+		p.pages[last] = append(p.pages[last], r)
+		p.used += r.GetHeight()
+	}
+}
+
+// keep appends rows on the current page when they fit, and on a new page
+// otherwise. Rows taller than a page are split anyway.
+func (p *paginator) keep(rows ...marotoCore.Row) {
+	if !p.fits(rowsHeight(rows)) && !p.empty() {
+		p.newPage()
+	}
+	p.append(rows...)
+}
+
+func (p *paginator) table(t *table) {
+	header := t.header(false)
+	for ix, group := range t.groups {
+		if ix == 0 {
+			p.keep(append(header, group...)...)
+			p.onBreak = func() { p.append(t.header(true)...) }
+			continue
+		}
+		p.keep(group...)
+	}
+	if len(t.groups) == 0 {
+		p.keep(header...)
+	}
+	p.onBreak = nil
+	p.append(t.footer...)
+}
+
+// paginate splits the layout into pages of the given useful height, below a
+// page header of headerHeight.
+func (l *layout) paginate(height, headerHeight float64) []marotoCore.Page {
+	p := &paginator{height: height, top: headerHeight}
+	p.newPage()
+	for _, block := range l.blocks {
+		if block.table != nil {
+			p.table(block.table)
+			continue
+		}
+		p.keep(block.rows...)
+	}
+
+	pages := make([]marotoCore.Page, 0, len(p.pages))
+	for _, rows := range p.pages {
+		pages = append(pages, page.New().Add(rows...))
+	}
+	return pages
+}
+
+func rowsHeight(rows []marotoCore.Row) float64 {
+	height := 0.0
+	for _, r := range rows {
+		height += r.GetHeight()
+	}
+	return height
+}
+
+// render lays out the body between the page header and footer, numbering
+// the pages "n of m" when the document spans more than one.
+func (b *Builder) render(head []marotoCore.Row, body *layout) ([]byte, error) {
+	cfg, err := b.marotoConfig()
+	if err != nil {
+		return nil, err
+	}
+	foot := b.BuildFooterRows()
+	height := cfg.Dimensions.Height - cfg.Margins.Top - cfg.Margins.Bottom - rowsHeight(foot)
+	pages := body.paginate(height, rowsHeight(head))
+	if len(pages) > 1 {
+		cfg.PageNumberPattern = b.i18nBundle.MusT(b.cfg.Lang, "PageNumber", nil)
+		cfg.PageNumberPlace = props.Bottom
+	}
+
+	m, err := newMetricsDecorator(cfg, head, foot)
+	if err != nil {
+		return nil, err
+	}
+	m.AddPages(pages...)
+	return b.getBytesFromMaroto(m)
+}
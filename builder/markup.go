@@ -0,0 +1,285 @@
+package builder
+
+import (
+	"html"
+	"html/template"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontfamily"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/jung-kurt/gofpdf"
+)
+
+type (
+	// markupSpan is a run of markdown-lite text in a single style.
+	markupSpan struct {
+		Text string
+		Bold bool
+	}
+
+	// markupBlock is a paragraph or a bullet of markdown-lite text. Line
+	// breaks of the source are kept.
+	markupBlock struct {
+		Bullet bool
+		Lines  [][]markupSpan
+	}
+)
+
+// parseMarkup parses markdown-lite text: paragraphs separated by blank
+// lines, bullets starting with "- " or "* ", continued by indented lines,
+// and **bold** spans.
+func parseMarkup(s string) []markupBlock {
+	blocks := []markupBlock{}
+	current := -1
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			current = -1
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			blocks = append(blocks, markupBlock{Bullet: true, Lines: [][]markupSpan{parseSpans(strings.TrimSpace(trimmed[2:]))}})
+			current = len(blocks) - 1
+		case current >= 0 && (!blocks[current].Bullet || line != strings.TrimLeft(line, " \t")):
+			blocks[current].Lines = append(blocks[current].Lines, parseSpans(trimmed))
+		default:
+			blocks = append(blocks, markupBlock{Lines: [][]markupSpan{parseSpans(trimmed)}})
+			current = len(blocks) - 1
+		}
+	}
+	return blocks
+}
+
+// parseSpans splits a line on "**" markers. An unmatched marker is kept as
+// text.
+func parseSpans(line string) []markupSpan {
+	parts := strings.Split(line, "**")
+	if len(parts)%2 == 0 {
+		last := len(parts) - 1
+		parts = append(parts[:last-1], parts[last-1]+"**"+parts[last])
+	}
+	spans := []markupSpan{}
+	for ix, part := range parts {
+		if part != "" {
+			spans = append(spans, markupSpan{Text: part, Bold: ix%2 == 1})
+		}
+	}
+	return spans
+}
+
+// markupHTML renders markdown-lite text as HTML.
+func markupHTML(s string) template.HTML {
+	buf := &strings.Builder{}
+	inList := false
+	for _, block := range parseMarkup(s) {
+		if block.Bullet && !inList {
+			buf.WriteString("<ul>")
+		} else if !block.Bullet && inList {
+			buf.WriteString("</ul>")
+		}
+		inList = block.Bullet
+		if block.Bullet {
+			buf.WriteString("<li>")
+		} else {
+			buf.WriteString("<p>")
+		}
+		for ix, line := range block.Lines {
+			if ix > 0 {
+				buf.WriteString("<br>")
+			}
+			for _, span := range line {
+				if span.Bold {
+					buf.WriteString("<strong>" + html.EscapeString(span.Text) + "</strong>")
+				} else {
+					buf.WriteString(html.EscapeString(span.Text))
+				}
+			}
+		}
+		if block.Bullet {
+			buf.WriteString("</li>")
+		} else {
+			buf.WriteString("</p>")
+		}
+	}
+	if inList {
+		buf.WriteString("</ul>")
+	}
+	return template.HTML(buf.String())
+}
+
+// textMeasurer measures strings in the fonts of the document, so that
+// markup is wrapped and its bold spans placed the way maroto renders them.
+type textMeasurer struct {
+	pdf       *gofpdf.Fpdf
+	family    string
+	styles    map[fontstyle.Type]bool
+	translate func(string) string
+	// width is the width of the content area of the page.
+	width float64
+}
+
+// measurer returns the text measurer of the builder, loading the fonts of
+// the document on first use.
+func (b *Builder) measurer() *textMeasurer {
+	if b.measurements != nil {
+		return b.measurements
+	}
+	cfg, err := b.marotoConfig()
+	if err != nil {
+		cfg = config.NewBuilder().Build()
+	}
+
+	m := &textMeasurer{
+		pdf:       gofpdf.New("P", "mm", "A4", ""),
+		family:    cfg.DefaultFont.Family,
+		styles:    map[fontstyle.Type]bool{},
+		translate: func(s string) string { return s },
+		width:     cfg.Dimensions.Width - cfg.Margins.Left - cfg.Margins.Right,
+	}
+	for _, font := range cfg.CustomFonts {
+		m.pdf.AddUTF8FontFromBytes(font.Family, string(font.Style), font.Bytes)
+		m.styles[font.Style] = true
+	}
+	switch m.family {
+	case fontfamily.Arial, fontfamily.Helvetica, fontfamily.Courier:
+		m.translate = m.pdf.UnicodeTranslatorFromDescriptor("")
+		m.styles[fontstyle.Normal] = true
+		m.styles[fontstyle.Bold] = true
+	}
+	if err := m.pdf.Error(); err != nil {
+		log.Printf("failed to load fonts to measure text: %v\n", err)
+		m.pdf = gofpdf.New("P", "mm", "A4", "")
+		m.family = fontfamily.Arial
+		m.styles = map[fontstyle.Type]bool{fontstyle.Normal: true, fontstyle.Bold: true}
+		m.translate = m.pdf.UnicodeTranslatorFromDescriptor("")
+	}
+	b.measurements = m
+	return m
+}
+
+func (m *textMeasurer) measure(s string, bold bool, size float64) float64 {
+	style := fontstyle.Normal
+	if bold && m.styles[fontstyle.Bold] {
+		style = fontstyle.Bold
+	}
+	m.pdf.SetFont(m.family, string(style), size)
+	return m.pdf.GetStringWidth(m.translate(s))
+}
+
+// wrap breaks a line of spans into lines no wider than width, between
+// words, or between characters for words wider than a line such as those
+// of Japanese text.
+func (m *textMeasurer) wrap(spans []markupSpan, width, size float64) [][]markupSpan {
+	lines := [][]markupSpan{{}}
+	used := 0.0
+	add := func(s string, bold bool) {
+		last := len(lines) - 1
+		if used == 0 {
+			s = strings.TrimLeft(s, " ")
+		}
+		w := m.measure(strings.TrimRight(s, " "), bold, size)
+		if used > 0 && used+w > width {
+			lines = append(lines, []markupSpan{})
+			last++
+			used = 0
+			s = strings.TrimLeft(s, " ")
+		}
+		if n := len(lines[last]); n > 0 && lines[last][n-1].Bold == bold {
+			lines[last][n-1].Text += s
+		} else if s != "" {
+			lines[last] = append(lines[last], markupSpan{Text: s, Bold: bold})
+		}
+		used += m.measure(s, bold, size)
+	}
+	for _, span := range spans {
+		for _, word := range strings.SplitAfter(span.Text, " ") {
+			if m.measure(strings.TrimSpace(word), span.Bold, size) <= width {
+				add(word, span.Bold)
+				continue
+			}
+			for len(word) > 0 {
+				_, n := utf8.DecodeRuneInString(word)
+				add(word[:n], span.Bold)
+				word = word[n:]
+			}
+		}
+	}
+	return lines
+}
+
+// markupRows lays out markdown-lite text across the page, returning the rows
+// of each paragraph or bullet as a group to keep together.
+func (b *Builder) markupRows(s string, size float64, color *props.Color) [][]marotoCore.Row {
+	const bulletIndent = 5.0
+	m := b.measurer()
+	lineHeight := size * 0.5
+	// maroto wraps text reaching the width of its column, leave it a margin
+	width := m.width - 1
+
+	blocks := parseMarkup(s)
+	groups := [][]marotoCore.Row{}
+	for ix, block := range blocks {
+		indent := 0.0
+		if block.Bullet {
+			indent = bulletIndent
+		}
+		rows := []marotoCore.Row{}
+		for _, source := range block.Lines {
+			for _, line := range m.wrap(source, width-indent, size) {
+				c := col.New(12)
+				if block.Bullet && len(rows) == 0 {
+					c.Add(text.New("•", props.Text{Size: size, Left: 1, Align: align.Left, Color: color}))
+				}
+				x := indent
+				for _, span := range line {
+					style := fontstyle.Normal
+					if span.Bold {
+						style = fontstyle.Bold
+					}
+					c.Add(text.New(span.Text, props.Text{Size: size, Left: x, Align: align.Left, Style: style, Color: color}))
+					x += m.measure(span.Text, span.Bold, size)
+				}
+				rows = append(rows, row.New(lineHeight).Add(c))
+			}
+		}
+		if !block.Bullet && ix < len(blocks)-1 {
+			rows = append(rows, row.New(lineHeight/2))
+		}
+		groups = append(groups, rows)
+	}
+	return groups
+}
+
+// markupSection lays out markdown-lite text under a section title, kept
+// together with its first paragraph.
+func (b *Builder) markupSection(title, s string) [][]marotoCore.Row {
+	sizes := b.theme().Typography
+	head := []marotoCore.Row{
+		row.New(b.headingHeight()).WithStyle(b.borderStyle()).Add(
+			text.NewCol(12, title, props.Text{Size: sizes.Subheading, Top: b.theme().Spacing.Section, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+		),
+		row.New(4),
+	}
+	groups := b.markupRows(s, sizes.Caption, b.fgSecondaryColor)
+	if len(groups) == 0 {
+		return [][]marotoCore.Row{head}
+	}
+	groups[0] = append(head, groups[0]...)
+	return groups
+}
+
+func flattenRows(groups [][]marotoCore.Row) []marotoCore.Row {
+	rows := []marotoCore.Row{}
+	for _, group := range groups {
+		rows = append(rows, group...)
+	}
+	return rows
+}
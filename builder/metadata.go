@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+// DocumentMetadata is the title, author, subject, keywords and creator
+// stored in generated PDFs.
+type DocumentMetadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Creator  string
+}
+
+// DefaultCreator is the creator application recorded in PDF metadata.
+const DefaultCreator = "bizdocgen"
+
+// documentTitles are the messages naming the document types in PDF
+// metadata.
+var documentTitles = map[DocumentType]string{
+	DocumentInvoice:          "MetadataTitleInvoice",
+	DocumentPaymentStatement: "MetadataTitlePaymentStatement",
+	DocumentQuote:            "MetadataTitleQuote",
+	DocumentReceipt:          "MetadataTitleReceipt",
+	DocumentCreditNote:       "MetadataTitleCreditNote",
+}
+
+// documentTitle returns the name of the document type in the document
+// language.
+func (b *Builder) documentTitle() string {
+	return b.i18nBundle.MusT(b.cfg.Lang, documentTitles[b.DocumentType()], nil)
+}
+
+// nonEmpty returns the parts that are not empty.
+func nonEmpty(parts ...string) []string {
+	out := []string{}
+	for _, part := range parts {
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// issuer returns the party issuing the document.
+func (b *Builder) issuer() string {
+	switch b.DocumentType() {
+	case DocumentReceipt:
+		return b.rParams.CompanyName
+	case DocumentPaymentStatement:
+		return b.psParams.Payer.Name
+	default:
+		return b.iParams.CompanyName
+	}
+}
+
+// subject returns what the document is about: the summary title of
+// invoices, quotes and credit notes and the purpose of receipts, or the
+// counterparty when params give none.
+func (b *Builder) subject() string {
+	info := b.Info()
+	subject := ""
+	switch info.Type {
+	case DocumentReceipt:
+		subject = b.rParams.For
+	case DocumentPaymentStatement:
+	default:
+		subject = b.iParams.Summary.Title
+	}
+	if subject == "" && info.Party != "" {
+		subject = b.i18nBundle.MusT(b.cfg.Lang, "MetadataSubjectParty", map[string]string{"Title": b.documentTitle(), "Party": info.Party})
+	}
+	return subject
+}
+
+// Metadata returns the metadata stored in the PDFs of the builder, such as
+// the title "Invoice 20240210-SAMPLE – ABC Inc", or "請求書 20240210-SAMPLE –
+// ABC株式会社" in Japanese, leaving out the parts params lack. Fields of
+// Config.Metadata override the values derived from params.
+func (b *Builder) Metadata() DocumentMetadata {
+	info := b.Info()
+	title := b.documentTitle()
+	m := DocumentMetadata{
+		Title:    strings.Join(nonEmpty(strings.Join(nonEmpty(title, info.ID), " "), b.issuer()), " – "),
+		Author:   b.issuer(),
+		Subject:  b.subject(),
+		Keywords: strings.Join(nonEmpty(title, info.ID, b.issuer(), info.Party), ", "),
+		Creator:  DefaultCreator,
+	}
+
+	override := b.cfg.Metadata
+	if override.Title != "" {
+		m.Title = override.Title
+	}
+	if override.Author != "" {
+		m.Author = override.Author
+	}
+	if override.Subject != "" {
+		m.Subject = override.Subject
+	}
+	if override.Keywords != "" {
+		m.Keywords = override.Keywords
+	}
+	if override.Creator != "" {
+		m.Creator = override.Creator
+	}
+	return m
+}
+
+// SuggestedFilename returns the file name to offer when the document is
+// downloaded, named after its type and ID like the batch outputs, e.g.
+// "invoice-20240210-SAMPLE.pdf". ext is the extension without the dot.
+func (b *Builder) SuggestedFilename(ext string) string {
+	info := b.Info()
+	return sanitizeFilename(fmt.Sprintf("%s-%s.%s", info.Type, info.ID, ext))
+}
+
+// documentUUID derives a stable name-based UUID URN from the document type,
+// issuer and number, so that every rendition of a document shares its XMP
+// DocumentID.
+func documentUUID(docType DocumentType, issuer, id string) string {
+	sum := sha1.Sum([]byte(string(docType) + "\x00" + issuer + "\x00" + id))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -2,17 +2,11 @@ package builder
 
 import (
 	"fmt"
-	"io"
-	"log"
-	"os"
 
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
-	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
-	"github.com/johnfercher/maroto/v2/pkg/consts/border"
-	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
@@ -20,59 +14,54 @@ import (
 )
 
 func (b *Builder) BuildPsHeader() ([]marotoCore.Row, error) {
+	sizes := b.theme().Typography
 	tTitle := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementTitle", nil)
 	tDate := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementIssueDate", nil)
 	tPeriod := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementPeriod", nil)
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
-	}
+	borderBottomStyle := b.borderStyle()
 	leftCol := col.New(6)
 
-	if b.psParams.CompanySeal != "" {
-		fd, err := os.Open(b.psParams.CompanySeal)
-		if err != nil {
-			log.Printf("failed to open seal file: %v\n", err)
-			return nil, err
-		}
-		defer fd.Close()
-		buf, err := io.ReadAll(fd)
-		if err != nil {
-			log.Printf("failed to read seal file: %v\n", err)
-			return nil, err
-		}
+	leftCol.Add(text.New(tTitle, props.Text{Size: sizes.Headline, Top: 8, Align: align.Left, Style: fontstyle.Bold}))
 
-		leftCol.Add(image.NewFromBytes(buf, extension.Png, props.Rect{
-			Center:  false,
-			Percent: 32,
-			Left:    0,
-			Top:     16,
-		}))
+	// statements have no issuer name in the header, the seal of the payer
+	// goes over the title
+	const headerHeight = 28
+	seal, err := b.sealComponent(b.psParams.CompanySeal, b.psParams.Payer.Name, 6, headerHeight, 8, sizes.Headline)
+	if err != nil {
+		return nil, err
+	}
+	if seal != nil {
+		leftCol.Add(seal)
 	}
 
-	leftCol.Add(text.New(tTitle, props.Text{Size: 14, Top: 8, Align: align.Left, Style: fontstyle.Bold}))
-
-	rs := row.New(28).WithStyle(borderBottomStyle).Add(
+	rs := row.New(headerHeight).WithStyle(borderBottomStyle).Add(
 		leftCol,
 		col.New(6).Add(
 			text.New(fmt.Sprintf("%s: %s", tDate, b.psParams.Date.Format("2006/01/02")),
-				props.Text{Size: 10, Top: 9, Align: align.Right}),
+				props.Text{Size: sizes.Subheading, Top: 9, Align: align.Right}),
 			text.New(fmt.Sprintf("%s: %s - %s",
 				tPeriod,
 				b.psParams.PeriodStart.Format("2006/01/02"),
 				b.psParams.PeriodEnd.Format("2006/01/02"),
-			), props.Text{Size: 10, Top: 16, Align: align.Right}),
+			), props.Text{Size: sizes.Subheading, Top: 16, Align: align.Right}),
 		),
 	)
 
 	rows := []marotoCore.Row{
 		rs,
 	}
-	return rows, nil
+
+	logo, err := b.BuildLogoRows(b.psParams.CompanyLogo)
+	if err != nil {
+		return nil, err
+	}
+	return append(logo, rows...), nil
 }
 
 func (b *Builder) BuildPsPayer() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tPayee := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementPayer", nil)
 	tName := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementUserName", nil)
 	tAddress := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementUserAddress", nil)
@@ -80,28 +69,30 @@ func (b *Builder) BuildPsPayer() []marotoCore.Row {
 	tContact := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementUserContact", nil)
 
 	return []marotoCore.Row{
-		text.NewRow(14, tPayee, props.Text{Size: 12, Top: 8, Style: fontstyle.Bold}),
+		text.NewRow(14, tPayee, props.Text{Size: sizes.Heading, Top: 8, Style: fontstyle.Bold}),
 
-		row.New(6).Add(
-			text.NewCol(4, tName, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(8, b.psParams.Payer.Name, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(4, tName, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(8, b.psParams.Payer.Name, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
-		row.New(6).Add(
-			text.NewCol(4, tAddress, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(8, b.psParams.Payer.Address, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(4, tAddress, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(8, b.psParams.Payer.Address, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
-		row.New(6).Add(
-			text.NewCol(4, tTaxID, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(8, b.psParams.Payer.TaxNumber, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(4, tTaxID, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(8, b.psParams.Payer.TaxNumber, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
-		row.New(6).Add(
-			text.NewCol(4, tContact, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(8, b.psParams.Payer.Contact, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(4, tContact, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(8, b.psParams.Payer.Contact, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
 	}
 }
 
 func (b *Builder) BuildPsPayee() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tPayee := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementPayee", nil)
 	tName := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementUserName", nil)
 	tAddress := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementUserAddress", nil)
@@ -109,61 +100,59 @@ func (b *Builder) BuildPsPayee() []marotoCore.Row {
 	tContact := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementUserContact", nil)
 
 	return []marotoCore.Row{
-		text.NewRow(14, tPayee, props.Text{Size: 12, Top: 8, Style: fontstyle.Bold}),
-		row.New(6).Add(
-			text.NewCol(3, tName, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(9, b.psParams.Payee.Name, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		text.NewRow(14, tPayee, props.Text{Size: sizes.Heading, Top: 8, Style: fontstyle.Bold}),
+		row.New(spacing.Line).Add(
+			text.NewCol(3, tName, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(9, b.psParams.Payee.Name, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
-		row.New(6).Add(
-			text.NewCol(3, tAddress, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(9, b.psParams.Payee.Address, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(3, tAddress, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(9, b.psParams.Payee.Address, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
-		row.New(6).Add(
-			text.NewCol(4, tTaxID, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(8, b.psParams.Payee.TaxNumber, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(4, tTaxID, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(8, b.psParams.Payee.TaxNumber, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
-		row.New(6).Add(
-			text.NewCol(4, tContact, props.Text{Size: 10, Top: 2, Align: align.Left}),
-			text.NewCol(8, b.psParams.Payee.Contact, props.Text{Size: 10, Top: 2, Align: align.Right}),
+		row.New(spacing.Line).Add(
+			text.NewCol(4, tContact, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Left}),
+			text.NewCol(8, b.psParams.Payee.Contact, props.Text{Size: sizes.Subheading, Top: 2, Align: align.Right}),
 		),
 		row.New(4),
 	}
 }
 
 func (b *Builder) BuildPsChannelRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tChannelTitle := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementChannelTitle", nil)
 	tChannel := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementChannel", nil)
 	tTxID := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementChannelTxID", nil)
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
-	}
+	borderBottomStyle := b.borderStyle()
 	return []marotoCore.Row{
-		row.New(16).WithStyle(borderBottomStyle).Add(
-			text.NewCol(8, tChannelTitle, props.Text{Size: 12, Top: 8, Align: align.Left, Style: fontstyle.Bold}),
+		row.New(b.headingHeight()).WithStyle(borderBottomStyle).Add(
+			text.NewCol(8, tChannelTitle, props.Text{Size: sizes.Heading, Top: spacing.Section, Align: align.Left, Style: fontstyle.Bold}),
 		),
 		row.New(10).Add(
-			text.NewCol(6, tChannel, props.Text{Size: 10, Top: 4, Align: align.Left}),
-			text.NewCol(6, b.psParams.PaymentChannel, props.Text{Size: 10, Top: 4, Align: align.Right}),
+			text.NewCol(6, tChannel, props.Text{Size: sizes.Subheading, Top: 4, Align: align.Left}),
+			text.NewCol(6, b.psParams.PaymentChannel, props.Text{Size: sizes.Subheading, Top: 4, Align: align.Right}),
 		),
 		row.New(12).Add(
-			text.NewCol(6, tTxID, props.Text{Size: 10, Top: 4, Align: align.Left}),
-			text.NewCol(6, b.psParams.PaymentTxID, props.Text{Size: 10, Top: 4, Align: align.Right}),
+			text.NewCol(6, tTxID, props.Text{Size: sizes.Subheading, Top: 4, Align: align.Left}),
+			text.NewCol(6, b.psParams.PaymentTxID, props.Text{Size: sizes.Subheading, Top: 4, Align: align.Right}),
 		),
 	}
 }
 
 func (b *Builder) BuildPsSummaryRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tSummary := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementSummary", nil)
 	tSummaryAmount := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementSummaryAmount", nil)
 	tRevenue := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementSummaryRevenue", nil)
 	tWithholdingTax := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementWithholdingTax", nil)
 	tNetAmount := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementSummaryNetAmount", nil)
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
-	}
+	borderBottomStyle := b.borderStyle()
 
 	total := decimal.NewFromFloat(0.0)
 	totalTax := decimal.NewFromFloat(0.0)
@@ -174,42 +163,55 @@ func (b *Builder) BuildPsSummaryRows() []marotoCore.Row {
 	}
 	totalWithoutTax := total.Sub(totalTax)
 
-	return []marotoCore.Row{
-		row.New(16).WithStyle(borderBottomStyle).Add(
-			text.NewCol(8, tSummary, props.Text{Size: 12, Top: 8, Align: align.Left, Style: fontstyle.Bold}),
-			text.NewCol(4, tSummaryAmount, props.Text{Size: 12, Top: 8, Align: align.Right, Style: fontstyle.Bold}),
+	rows := []marotoCore.Row{
+		row.New(b.headingHeight()).WithStyle(borderBottomStyle).Add(
+			text.NewCol(8, tSummary, props.Text{Size: sizes.Heading, Top: spacing.Section, Align: align.Left, Style: fontstyle.Bold}),
+			text.NewCol(4, tSummaryAmount, props.Text{Size: sizes.Heading, Top: spacing.Section, Align: align.Right, Style: fontstyle.Bold}),
 		),
 		row.New(14).Add(
-			text.NewCol(8, tRevenue, props.Text{Size: 10, Top: 4, Align: align.Left}),
-			text.NewCol(4, fmt.Sprintf("%s %s", total.Round(b.Round), b.psParams.Currency), props.Text{Size: 10, Top: 4, Align: align.Right}),
+			text.NewCol(8, tRevenue, props.Text{Size: sizes.Subheading, Top: 4, Align: align.Left}),
+			text.NewCol(4, fmt.Sprintf("%s %s", total.Round(b.Round), b.psParams.Currency), props.Text{Size: sizes.Subheading, Top: 4, Align: align.Right}),
 		),
 		row.New(10).WithStyle(borderBottomStyle).Add(
-			text.NewCol(6, tWithholdingTax, props.Text{Size: 10, Top: 0, Align: align.Left}),
-			text.NewCol(6, fmt.Sprintf("-%s %s", totalTax.Round(b.Round), b.psParams.Currency), props.Text{Size: 10, Top: 0, Align: align.Right}),
+			text.NewCol(6, tWithholdingTax, props.Text{Size: sizes.Subheading, Top: 0, Align: align.Left}),
+			text.NewCol(6, fmt.Sprintf("-%s %s", totalTax.Round(b.Round), b.psParams.Currency), props.Text{Size: sizes.Subheading, Top: 0, Align: align.Right}),
 		),
 		row.New(16).Add(
-			text.NewCol(6, tNetAmount, props.Text{Size: 12, Top: 4, Align: align.Left, Style: fontstyle.Bold}),
-			text.NewCol(6, fmt.Sprintf("%s %s", totalWithoutTax.Round(b.Round), b.psParams.Currency), props.Text{Size: 12, Top: 4, Align: align.Right, Style: fontstyle.Bold}),
+			text.NewCol(6, tNetAmount, props.Text{Size: sizes.Heading, Top: 4, Align: align.Left, Style: fontstyle.Bold}),
+			text.NewCol(6, fmt.Sprintf("%s %s", totalWithoutTax.Round(b.Round), b.psParams.Currency), props.Text{Size: sizes.Heading, Top: 4, Align: align.Right, Style: fontstyle.Bold}),
 		),
 	}
+	return append(rows, b.BuildAmountInWordsRows(totalWithoutTax, b.psParams.Currency, align.Right)...)
 }
 
 func (b *Builder) BuildPsDetailsRows() []marotoCore.Row {
+	return b.psDetailsTable().rows()
+}
+
+// psDetailsTable builds the detail items of the payment statement as a
+// table, one row per item.
+func (b *Builder) psDetailsTable() *table {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
 	tDetails := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementDetails", nil)
 	tAmount := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementDetailsAmount", nil)
 	tTax := b.i18nBundle.MusT(b.cfg.Lang, "PaymentStatementDetailsTax", nil)
 
-	borderBottomStyle := &props.Cell{
-		BorderType:  border.Bottom,
-		BorderColor: &props.Color{Red: 200, Green: 200, Blue: 200},
-	}
+	borderBottomStyle := b.borderStyle()
 
-	rows := []marotoCore.Row{
-		row.New(16).WithStyle(borderBottomStyle).Add(
-			text.NewCol(4, tDetails, props.Text{Size: 12, Top: 8, Align: align.Left, Style: fontstyle.Bold}),
-			text.NewCol(4, tAmount, props.Text{Size: 12, Top: 8, Align: align.Right, Style: fontstyle.Bold}),
-			text.NewCol(4, tTax, props.Text{Size: 12, Top: 8, Align: align.Right, Style: fontstyle.Bold}),
-		),
+	t := &table{}
+	t.header = func(continued bool) []marotoCore.Row {
+		title := tDetails
+		if continued {
+			title = b.i18nBundle.MusT(b.cfg.Lang, "Continued", map[string]string{"Title": tDetails})
+		}
+		return []marotoCore.Row{
+			row.New(b.headingHeight()).WithStyle(borderBottomStyle).Add(
+				text.NewCol(4, title, props.Text{Size: sizes.Heading, Top: spacing.Section, Align: align.Left, Style: fontstyle.Bold}),
+				text.NewCol(4, tAmount, props.Text{Size: sizes.Heading, Top: spacing.Section, Align: align.Right, Style: fontstyle.Bold}),
+				text.NewCol(4, tTax, props.Text{Size: sizes.Heading, Top: spacing.Section, Align: align.Right, Style: fontstyle.Bold}),
+			),
+		}
 	}
 
 	for ix, item := range b.psParams.DetailItems {
@@ -221,18 +223,18 @@ func (b *Builder) BuildPsDetailsRows() []marotoCore.Row {
 		}
 		tax := item.Amount.Mul(item.WithholdingTaxRate)
 		netAmount := item.Amount.Sub(tax)
-		rows = append(rows, row.New(rowHeight).Add(
+		t.groups = append(t.groups, []marotoCore.Row{row.New(rowHeight).Add(
 			col.New(4).Add(
-				text.New(item.Title, props.Text{Size: 10, Top: paddingTop, Align: align.Left}),
+				text.New(item.Title, props.Text{Size: sizes.Subheading, Top: paddingTop, Align: align.Left}),
 			),
 			col.New(4).Add(
-				text.New(fmt.Sprintf("%s %s", netAmount.Round(b.Round), b.psParams.Currency), props.Text{Size: 10, Top: paddingTop, Align: align.Right}),
+				text.New(fmt.Sprintf("%s %s", netAmount.Round(b.Round), b.psParams.Currency), props.Text{Size: sizes.Subheading, Top: paddingTop, Align: align.Right}),
 			),
 			col.New(4).Add(
-				text.New(fmt.Sprintf("%s %s", tax.Round(b.Round), b.psParams.Currency), props.Text{Size: 10, Top: paddingTop, Align: align.Right}),
+				text.New(fmt.Sprintf("%s %s", tax.Round(b.Round), b.psParams.Currency), props.Text{Size: sizes.Subheading, Top: paddingTop, Align: align.Right}),
 			),
-		))
+		)})
 
 	}
-	return rows
+	return t
 }
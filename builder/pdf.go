@@ -4,64 +4,116 @@ import (
 	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/core/entity"
 
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
 	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	"github.com/johnfercher/maroto/v2/pkg/props"
 	"github.com/johnfercher/maroto/v2/pkg/repository"
 )
 
 func (b *Builder) CreateMetricsDecorator(head []marotoCore.Row) (marotoCore.Maroto, error) {
-	repo := repository.New()
+	cfg, err := b.marotoConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newMetricsDecorator(cfg, head, b.BuildFooterRows())
+}
+
+// marotoConfig returns the maroto configuration of the builder, with the
+// custom fonts loaded.
+func (b *Builder) marotoConfig() (*entity.Config, error) {
 	useCustomFonts := false
-	customFontsAdded := false
-	var customFonts []*entity.CustomFont
+	customFonts := b.cfg.CustomFonts
 	if b.cfg.FontName == "" {
 		b.cfg.FontName = "default-font"
 	}
-	if b.cfg.FontNormal != "" || b.cfg.FontItalic != "" || b.cfg.FontBold != "" || b.cfg.FontBoldItalic != "" {
-		if b.cfg.FontNormal != "" {
-			repo = repo.AddUTF8Font(b.cfg.FontName, fontstyle.Normal, b.cfg.FontNormal)
-		}
-		if b.cfg.FontItalic != "" {
-			repo = repo.AddUTF8Font(b.cfg.FontName, fontstyle.Italic, b.cfg.FontItalic)
-		}
-		if b.cfg.FontBold != "" {
-			repo = repo.AddUTF8Font(b.cfg.FontName, fontstyle.Bold, b.cfg.FontBold)
-		}
-		if b.cfg.FontBoldItalic != "" {
-			repo = repo.AddUTF8Font(b.cfg.FontName, fontstyle.BoldItalic, b.cfg.FontBoldItalic)
-		}
-		customFontsAdded = true
-	}
-	if customFontsAdded {
+	if customFonts != nil {
+		useCustomFonts = true
+	} else if b.cfg.FontNormal != "" || b.cfg.FontItalic != "" || b.cfg.FontBold != "" || b.cfg.FontBoldItalic != "" {
 		var err error
-		customFonts, err = repo.Load()
+		customFonts, err = LoadFonts(b.cfg)
 		if err != nil {
 			log.Printf("failed to load custom fonts: %v\n", err)
 		} else {
 			useCustomFonts = true
+			b.cfg.CustomFonts = customFonts
 		}
 	}
 
+	if level := b.pdfaLevel(); level != "" && (!useCustomFonts || len(customFonts) == 0) {
+		log.Printf("PDF/A output requires custom fonts\n")
+		return nil, fmt.Errorf("PDF/A-%s output requires custom fonts to be embedded", level)
+	}
+
 	bu := config.NewBuilder()
-	// bu = bu.WithPageNumber("Page {current} of {total}", props.Bottom)
 	if useCustomFonts {
 		bu = bu.WithCustomFonts(customFonts)
 		bu = bu.WithDefaultFont(&props.Font{Family: b.cfg.FontName})
 	}
+	return bu.Build(), nil
+}
 
-	cfg := bu.Build()
-
-	mrt := maroto.New(cfg)
-
-	m := maroto.NewMetricsDecorator(mrt)
-
+func newMetricsDecorator(cfg *entity.Config, head, foot []marotoCore.Row) (marotoCore.Maroto, error) {
+	m := maroto.NewMetricsDecorator(maroto.New(cfg))
 	if err := m.RegisterHeader(head...); err != nil {
 		log.Printf("failed to register header: %v\n", err)
 		return nil, err
 	}
+	if len(foot) > 0 {
+		if err := m.RegisterFooter(foot...); err != nil {
+			log.Printf("failed to register footer: %v\n", err)
+			return nil, err
+		}
+	}
 	return m, nil
 }
+
+// footer returns the footer of the document language, or the default one.
+func (b *Builder) footer() string {
+	if footer, ok := b.cfg.Footer[b.cfg.Lang]; ok {
+		return footer
+	}
+	return b.cfg.Footer[""]
+}
+
+// BuildFooterRows returns the rows of the footer repeated at the bottom of
+// every page, if any.
+func (b *Builder) BuildFooterRows() []marotoCore.Row {
+	footer := b.footer()
+	if strings.TrimSpace(footer) == "" {
+		return nil
+	}
+	rows := []marotoCore.Row{
+		row.New(3).WithStyle(b.separatorStyle(border.Top)),
+	}
+	return append(rows, flattenRows(b.markupRows(footer, b.theme().Typography.Footnote, b.fgSecondaryColor))...)
+}
+
+// LoadFonts reads the custom fonts configured in cfg, so that they can be
+// set as Config.CustomFonts and shared by many builders.
+func LoadFonts(cfg Config) ([]*entity.CustomFont, error) {
+	fontName := cfg.FontName
+	if fontName == "" {
+		fontName = "default-font"
+	}
+	repo := repository.New()
+	if cfg.FontNormal != "" {
+		repo = repo.AddUTF8Font(fontName, fontstyle.Normal, cfg.FontNormal)
+	}
+	if cfg.FontItalic != "" {
+		repo = repo.AddUTF8Font(fontName, fontstyle.Italic, cfg.FontItalic)
+	}
+	if cfg.FontBold != "" {
+		repo = repo.AddUTF8Font(fontName, fontstyle.Bold, cfg.FontBold)
+	}
+	if cfg.FontBoldItalic != "" {
+		repo = repo.AddUTF8Font(fontName, fontstyle.BoldItalic, cfg.FontBoldItalic)
+	}
+	return repo.Load()
+}
@@ -0,0 +1,85 @@
+package builder
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/quail-ink/bizdocgen/pdfa"
+)
+
+// pdfMetadata describes the document for the information dictionary and
+// the XMP metadata.
+func (b *Builder) pdfMetadata() pdfa.Metadata {
+	info := b.Info()
+	meta := b.Metadata()
+	m := pdfa.Metadata{
+		Title:      meta.Title,
+		Author:     meta.Author,
+		Subject:    meta.Subject,
+		Keywords:   meta.Keywords,
+		Creator:    meta.Creator,
+		DocumentID: documentUUID(info.Type, b.issuer(), info.ID),
+	}
+	if b.embedsFacturX() {
+		m.Extension = b.facturXMetadata()
+	}
+	return m
+}
+
+// pdfaLevel returns the PDF/A level of the documents, PDF/A-3b for
+// Factur-X invoices without Config.PDFA, "" when they are plain PDFs.
+func (b *Builder) pdfaLevel() pdfa.Level {
+	if b.cfg.PDFA == "" && b.embedsFacturX() {
+		return pdfa.Level3B
+	}
+	return b.cfg.PDFA
+}
+
+// postProcess completes the PDF rendered by maroto: it sets the document
+// metadata, embeds the Factur-X invoice and applies the PDF/A metadata.
+// Factur-X invoices are PDF/A-3 even without Config.PDFA; the result is
+// verified and refused when it does not conform.
+func (b *Builder) postProcess(pdf []byte) ([]byte, error) {
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, err
+	}
+	level := b.pdfaLevel()
+	if level == "" {
+		if err := pdfa.SetInfo(ctx, b.pdfMetadata()); err != nil {
+			return nil, err
+		}
+		out := &bytes.Buffer{}
+		if err := api.WriteContext(ctx, out); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+
+	if b.embedsFacturX() {
+		if err := b.embedFacturX(ctx); err != nil {
+			log.Printf("failed to embed Factur-X invoice: %v\n", err)
+			return nil, err
+		}
+	}
+	if err := pdfa.Apply(ctx, level, b.pdfMetadata()); err != nil {
+		log.Printf("failed to apply PDF/A metadata: %v\n", err)
+		return nil, err
+	}
+	pdf, err = pdfa.Write(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := pdfa.Verify(bytes.NewReader(pdf))
+	if err != nil {
+		return nil, err
+	}
+	if err := report.Err(); err != nil {
+		log.Printf("document is not PDF/A-%s: %v\n", level, err)
+		return nil, err
+	}
+	return pdf, nil
+}
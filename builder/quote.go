@@ -0,0 +1,13 @@
+package builder
+
+import (
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+)
+
+func (b *Builder) BuildQuoteTermsRows() []marotoCore.Row {
+	return flattenRows(b.quoteTermsSection())
+}
+
+func (b *Builder) quoteTermsSection() [][]marotoCore.Row {
+	return b.markupSection(b.i18nBundle.MusT(b.cfg.Lang, "QuoteTerms", nil), b.qParams.Terms)
+}
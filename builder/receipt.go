@@ -0,0 +1,192 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+func (b *Builder) BuildReceiptHeader() ([]marotoCore.Row, error) {
+	sizes := b.theme().Typography
+	spacing := b.theme().Spacing
+	tTitle := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptTitle", nil)
+	tReceiptID := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptID", nil)
+	tIssueDate := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptIssueDate", nil)
+
+	borderBottomStyle := b.borderStyle()
+
+	logo, err := b.BuildLogoRows(b.rParams.CompanyLogo)
+	if err != nil {
+		return nil, err
+	}
+	return append(logo,
+		row.New(20).WithStyle(borderBottomStyle).Add(
+			text.NewCol(6, tTitle, props.Text{Size: sizes.Title, Top: 6, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+			col.New(6).Add(
+				text.New(fmt.Sprintf("%s: %s", tReceiptID, b.rParams.ID), props.Text{Size: sizes.Body, Top: 6, Align: align.Right, Color: b.fgColor}),
+				text.New(fmt.Sprintf("%s: %s", tIssueDate, b.rParams.Date.Format("2006/01/02")), props.Text{Size: sizes.Body, Top: 12, Align: align.Right, Color: b.fgColor}),
+			),
+		),
+		row.New(spacing.Line),
+	), nil
+}
+
+func (b *Builder) BuildReceiptAmountRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	tRecipient := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptRecipient", map[string]string{"Name": b.rParams.ReceivedFrom})
+	tAmount := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptAmount", nil)
+	tAcknowledge := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptAcknowledgement", nil)
+
+	boxStyle := &props.Cell{
+		BorderType:      border.Full,
+		BorderColor:     b.fgColor,
+		BorderThickness: 0.6,
+	}
+
+	rows := []marotoCore.Row{
+		row.New(14).Add(
+			text.NewCol(8, tRecipient, props.Text{Size: sizes.Headline, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}),
+		),
+		row.New(4),
+		row.New(22).Add(
+			col.New(2),
+			col.New(8).WithStyle(boxStyle).Add(
+				text.New(tAmount, props.Text{Size: sizes.Body, Top: 2, Left: 2, Align: align.Left, Color: b.fgSecondaryColor}),
+				text.New(b.receiptAmountText(), props.Text{Size: sizes.Display, Top: 8, Align: align.Center, Style: fontstyle.Bold, Color: b.fgColor}),
+			),
+			col.New(2),
+		),
+	}
+	if words := b.BuildAmountInWordsRows(b.rParams.Amount, b.rParams.Currency, align.Center); words != nil {
+		rows = append(rows, row.New(2))
+		rows = append(rows, words...)
+	}
+	return append(rows, row.New(10).Add(
+		text.NewCol(12, tAcknowledge, props.Text{Size: sizes.Body, Top: 4, Align: align.Center, Color: b.fgColor}),
+	))
+}
+
+func (b *Builder) BuildReceiptDetailsRows() []marotoCore.Row {
+	sizes := b.theme().Typography
+	tFor := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptFor", nil)
+	tMethod := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptPaymentMethod", nil)
+	tInvoiceID := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceID", nil)
+	tSubtotal := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptSubtotal", nil)
+	tVAT := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceSummaryVAT", nil)
+
+	borderBottomStyle := b.borderStyle()
+
+	type line struct {
+		label string
+		value string
+	}
+	lines := []line{{tFor, b.rParams.For}}
+	if !b.rParams.Tax.IsZero() {
+		subtotal := b.rParams.Amount.Sub(b.rParams.Tax)
+		lines = append(lines,
+			line{tSubtotal, fmt.Sprintf("%s %s", subtotal.Round(b.Round), b.rParams.Currency)},
+			line{tVAT, fmt.Sprintf("%s %s", b.rParams.Tax.Round(b.Round), b.rParams.Currency)},
+		)
+	}
+	if b.rParams.PaymentMethod != "" {
+		lines = append(lines, line{tMethod, b.rParams.PaymentMethod})
+	}
+	if b.rParams.InvoiceID != "" {
+		lines = append(lines, line{tInvoiceID, b.rParams.InvoiceID})
+	}
+
+	rows := []marotoCore.Row{row.New(4)}
+	for _, l := range lines {
+		rows = append(rows, row.New(8).WithStyle(borderBottomStyle).Add(
+			text.NewCol(4, l.label, props.Text{Size: sizes.Body, Top: 2, Align: align.Left, Color: b.fgColor}),
+			text.NewCol(8, l.value, props.Text{Size: sizes.Body, Top: 2, Align: align.Right, Color: b.fgColor}),
+		))
+	}
+	return rows
+}
+
+func (b *Builder) BuildReceiptIssuerRows() ([]marotoCore.Row, error) {
+	sizes := b.theme().Typography
+	stampStyle := &props.Cell{
+		BorderType:  border.Full,
+		BorderColor: &b.theme().Palette.Border,
+	}
+
+	stampCol := col.New(3)
+	if b.rParams.RequiresRevenueStamp() {
+		tStamp := b.i18nBundle.MusT(b.cfg.Lang, "ReceiptRevenueStamp", nil)
+		stampCol.WithStyle(stampStyle).Add(
+			text.New(tStamp, props.Text{Size: sizes.Caption, Top: 14, Align: align.Center, Color: b.fgSecondaryColor}),
+		)
+	}
+
+	issuerCol := col.New(6)
+	issuerCol.Add(text.New(b.rParams.CompanyName, props.Text{Size: sizes.Heading, Top: 4, Align: align.Left, Style: fontstyle.Bold, Color: b.fgColor}))
+	lines := strings.Split(b.rParams.CompanyAddr, "\n")
+	for ix, line := range lines {
+		issuerCol.Add(text.New(line, props.Text{Size: sizes.Body, Top: float64(6*ix + 12), Align: align.Left, Color: b.fgColor}))
+	}
+	if b.rParams.TaxNumber != "" {
+		tTaxID := b.i18nBundle.MusT(b.cfg.Lang, "InvoiceTaxID", nil)
+		issuerCol.Add(text.New(fmt.Sprintf("%s: %s", tTaxID, b.rParams.TaxNumber), props.Text{Size: sizes.Body, Top: float64(6*len(lines) + 12), Align: align.Left, Color: b.fgColor}))
+	}
+
+	const issuerHeight = 34
+	seal, err := b.sealComponent(b.rParams.CompanySeal, b.rParams.CompanyName, 6, issuerHeight, 4, sizes.Heading)
+	if err != nil {
+		return nil, err
+	}
+	if seal != nil {
+		issuerCol.Add(seal)
+	}
+
+	return []marotoCore.Row{
+		row.New(8),
+		row.New(issuerHeight).Add(
+			stampCol,
+			col.New(3),
+			issuerCol,
+		),
+	}, nil
+}
+
+// receiptAmountText formats the received amount the way it is written on a
+// receipt, e.g. "¥550,000-" for yen.
+func (b *Builder) receiptAmountText() string {
+	amount := groupThousands(b.rParams.Amount.Round(b.Round), b.Round)
+	if core.IsJPY(b.rParams.Currency) {
+		return fmt.Sprintf("¥%s-", amount)
+	}
+	return fmt.Sprintf("%s %s", amount, b.rParams.Currency)
+}
+
+// groupThousands formats the amount with comma separated thousands.
+func groupThousands(amount decimal.Decimal, places int32) string {
+	s := amount.Abs().StringFixed(places)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	var sb strings.Builder
+	if amount.IsNegative() {
+		sb.WriteString("-")
+	}
+	for ix, r := range intPart {
+		if ix > 0 && (len(intPart)-ix)%3 == 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteRune(r)
+	}
+	if fracPart != "" {
+		sb.WriteString(".")
+		sb.WriteString(fracPart)
+	}
+	return sb.String()
+}
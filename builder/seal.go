@@ -0,0 +1,334 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+type (
+	// SealPlacement is the position, size and opacity of the company seal
+	// in the page header, and the stamp drawn when params have no seal.
+	SealPlacement struct {
+		// Anchor is what the seal is positioned from, SealAnchorName by
+		// default.
+		Anchor SealAnchor
+		// Left and Top move the seal from its anchor, in millimetres.
+		Left float64
+		Top  float64
+		// Size is the width of the seal in millimetres, 15 by default.
+		Size float64
+		// Opacity is the opacity of the seal from 0 to 1, 0.9 by default,
+		// letting the issuer name show through like ink.
+		Opacity float64
+		// Stamp draws a stamp of the issuer name in the given shape when
+		// params have no seal image. Names outside Latin scripts need
+		// custom fonts covering them.
+		Stamp StampShape
+	}
+
+	// SealAnchor is what the seal is positioned from.
+	SealAnchor string
+
+	// StampShape is the shape of generated seals.
+	StampShape string
+)
+
+const (
+	// SealAnchorName centres the seal on the end of the issuer name, the
+	// way hanko are stamped over the name and address.
+	SealAnchorName SealAnchor = "name"
+	// SealAnchorBlock places the seal from the top left corner of the
+	// issuer block.
+	SealAnchorBlock SealAnchor = "block"
+
+	StampCircle StampShape = "circle"
+	StampSquare StampShape = "square"
+)
+
+// stampSize is the size in pixels of generated seals.
+const stampSize = 512
+
+// stampColor is the vermilion of seal ink.
+var stampColor = color.NRGBA{R: 200, G: 34, B: 34, A: 255}
+
+// sealSize returns the width of the seal in millimetres.
+func (b *Builder) sealSize() float64 {
+	if b.cfg.Seal.Size > 0 {
+		return b.cfg.Seal.Size
+	}
+	return 15
+}
+
+// sealOpacity returns the opacity of the seal.
+func (b *Builder) sealOpacity() float64 {
+	if b.cfg.Seal.Opacity > 0 && b.cfg.Seal.Opacity < 1 {
+		return b.cfg.Seal.Opacity
+	}
+	if b.cfg.Seal.Opacity >= 1 {
+		return 1
+	}
+	return 0.9
+}
+
+// loadSeal returns the seal image referenced by params, a stamp of name
+// when there is none and Config.Seal.Stamp is set, or nil.
+func (b *Builder) loadSeal(ref, name string) (*docImage, error) {
+	if ref != "" {
+		return b.loadImage(ref)
+	}
+	if b.cfg.Seal.Stamp == "" || strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+	seal, err := b.generateSeal(name)
+	if err != nil {
+		log.Printf("failed to generate seal: %v\n", err)
+		return nil, err
+	}
+	return seal, nil
+}
+
+// sealRect returns the rectangle of the seal in a column of the given
+// width and row height, where the issuer name is drawn at nameTop in bold
+// of nameSize points.
+func (b *Builder) sealRect(seal *docImage, colWidth, rowHeight float64, name string, nameTop, nameSize float64) props.Rect {
+	width := b.sealSize()
+	height := width * float64(seal.height) / float64(seal.width)
+
+	// maroto sizes images in percent of the cell, fitting the side which
+	// is the tightest
+	rect := props.Rect{Percent: width / colWidth * 100}
+	if height/width > rowHeight/colWidth {
+		rect.Percent = height / rowHeight * 100
+	}
+	rect.Percent = math.Min(rect.Percent, 100)
+
+	rect.Left, rect.Top = b.cfg.Seal.Left, b.cfg.Seal.Top
+	if b.cfg.Seal.Anchor != SealAnchorBlock {
+		nameWidth := b.measurer().measure(name, true, nameSize)
+		rect.Left += nameWidth - width/2
+		rect.Top += nameTop + nameSize*ptToMM/2 - height/2
+	}
+	return rect
+}
+
+// sealComponent returns the seal of the issuer in a header column of the
+// given grid size and row height, nil when there is none.
+func (b *Builder) sealComponent(ref, name string, cols int, rowHeight, nameTop, nameSize float64) (marotoCore.Component, error) {
+	seal, err := b.loadSeal(ref, name)
+	if err != nil || seal == nil {
+		return nil, err
+	}
+	rect := b.sealRect(seal, b.measurer().width*float64(cols)/12, rowHeight, name, nameTop, nameSize)
+	if seal, err = seal.withOpacity(b.sealOpacity()); err != nil {
+		log.Printf("failed to fade seal: %v\n", err)
+		return nil, err
+	}
+	return seal.component(rect), nil
+}
+
+// ptToMM converts font sizes in points to millimetres.
+const ptToMM = 25.4 / 72
+
+// htmlSeal returns the seal of HTML documents, positioned by CSS the way
+// sealRect positions it in PDFs.
+func (b *Builder) htmlSeal(ref, name string) (htmlSealImage, error) {
+	seal, err := b.loadSeal(ref, name)
+	if err != nil || seal == nil {
+		return htmlSealImage{}, err
+	}
+	width := b.sealSize()
+	height := width * float64(seal.height) / float64(seal.width)
+	view := htmlSealImage{Src: seal.dataURL(), OnName: b.cfg.Seal.Anchor != SealAnchorBlock}
+	if view.OnName {
+		view.Style = template.CSS(fmt.Sprintf("left: calc(100%% - %gmm); top: calc(50%% - %gmm); ", width/2-b.cfg.Seal.Left, height/2-b.cfg.Seal.Top))
+	} else {
+		view.Style = template.CSS(fmt.Sprintf("left: %gmm; top: %gmm; ", b.cfg.Seal.Left, b.cfg.Seal.Top))
+	}
+	view.Style += template.CSS(fmt.Sprintf("width: %gmm; opacity: %g;", width, b.sealOpacity()))
+	return view, nil
+}
+
+// withOpacity returns the image with its alpha multiplied by opacity, as
+// maroto draws images opaque.
+func (img *docImage) withOpacity(opacity float64) (*docImage, error) {
+	if opacity >= 1 {
+		return img, nil
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(img.data))
+	if err != nil {
+		return nil, err
+	}
+	faded := image.NewNRGBA(decoded.Bounds())
+	draw.Draw(faded, faded.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+	for ix := 3; ix < len(faded.Pix); ix += 4 {
+		faded.Pix[ix] = uint8(float64(faded.Pix[ix]) * opacity)
+	}
+	out := *img
+	return &out, out.setPNG(faded)
+}
+
+// generateSeal draws a seal of the issuer name: its characters in columns
+// read from the right for Japanese and Chinese names, its words in lines
+// otherwise, inside a circle or square border.
+func (b *Builder) generateSeal(name string) (*docImage, error) {
+	f, err := opentype.Parse(b.stampFont())
+	if err != nil {
+		return nil, err
+	}
+	canvas := image.NewNRGBA(image.Rect(0, 0, stampSize, stampSize))
+
+	// the border, anti-aliased by the distance of pixels to its centre
+	const stroke = stampSize * 0.045
+	radius := stampSize/2 - stroke/2 - 2
+	inner := (radius - stroke) * 2
+	for y := 0; y < stampSize; y++ {
+		for x := 0; x < stampSize; x++ {
+			dx, dy := float64(x)+0.5-stampSize/2, float64(y)+0.5-stampSize/2
+			d := math.Max(math.Abs(dx), math.Abs(dy))
+			if b.cfg.Seal.Stamp == StampCircle {
+				d = math.Hypot(dx, dy)
+			}
+			if coverage := stroke/2 - math.Abs(d-radius) + 0.5; coverage > 0 {
+				c := stampColor
+				c.A = uint8(255 * math.Min(coverage, 1))
+				canvas.SetNRGBA(x, y, c)
+			}
+		}
+	}
+	if b.cfg.Seal.Stamp == StampCircle {
+		inner /= math.Sqrt2
+	}
+	inner *= 0.9
+
+	lines := stampLines(name)
+	vertical := isCJK(name)
+	cells := len(lines)
+	for _, line := range lines {
+		if vertical {
+			cells = max(cells, len(line))
+		}
+	}
+	cell := inner / float64(cells)
+	size := cell * 0.92
+	if !vertical {
+		// fit the widest line into the border
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+		if err != nil {
+			return nil, err
+		}
+		widest := 0.0
+		for _, line := range lines {
+			widest = math.Max(widest, float64(font.MeasureString(face, string(line)))/64)
+		}
+		face.Close()
+		if widest > inner {
+			size *= inner / widest
+		}
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	d := &font.Drawer{Dst: canvas, Src: image.NewUniform(stampColor), Face: face}
+	metrics := face.Metrics()
+	textHeight := float64(metrics.Ascent+metrics.Descent) / 64
+	ascent := float64(metrics.Ascent) / 64
+	origin := (stampSize - inner) / 2
+	drawCentered := func(s string, cx, cy float64) {
+		w := float64(font.MeasureString(face, s)) / 64
+		d.Dot = fixed.P(int(cx-w/2), int(cy-textHeight/2+ascent))
+		d.DrawString(s)
+	}
+	if vertical {
+		// columns read from the right, centred in the border
+		left := origin + (inner-cell*float64(len(lines)))/2
+		for ix, line := range lines {
+			cx := left + cell*(float64(len(lines)-ix)-0.5)
+			top := origin + (inner-cell*float64(len(line)))/2
+			for iy, r := range line {
+				drawCentered(string(r), cx, top+cell*(float64(iy)+0.5))
+			}
+		}
+	} else {
+		top := origin + (inner-cell*float64(len(lines)))/2
+		for iy, line := range lines {
+			drawCentered(string(line), stampSize/2, top+cell*(float64(iy)+0.5))
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, canvas); err != nil {
+		return nil, err
+	}
+	return decodeImage(buf.Bytes())
+}
+
+// stampLines splits the name into the columns of a CJK seal, filled as
+// evenly as possible, or into its words.
+func stampLines(name string) [][]rune {
+	if !isCJK(name) {
+		lines := [][]rune{}
+		for _, word := range strings.Fields(name) {
+			lines = append(lines, []rune(word))
+		}
+		return lines
+	}
+	runes := []rune(strings.Join(strings.Fields(name), ""))
+	rows := int(math.Ceil(math.Sqrt(float64(len(runes)))))
+	lines := [][]rune{}
+	for len(runes) > 0 {
+		n := min(rows, len(runes))
+		lines = append(lines, runes[:n])
+		runes = runes[n:]
+	}
+	return lines
+}
+
+// isCJK reports whether the name is written in Han, Hiragana or Katakana.
+func isCJK(name string) bool {
+	for _, r := range name {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			return true
+		}
+	}
+	return false
+}
+
+// stampFont returns the bold custom font, or any custom font, falling back
+// to Go Bold which only covers Latin scripts.
+func (b *Builder) stampFont() []byte {
+	if cfg, err := b.marotoConfig(); err == nil {
+		var fallback []byte
+		for _, f := range cfg.CustomFonts {
+			if f.Style == fontstyle.Bold {
+				return f.Bytes
+			}
+			if fallback == nil {
+				fallback = f.Bytes
+			}
+		}
+		if fallback != nil {
+			return fallback
+		}
+	}
+	return gobold.TTF
+}
@@ -0,0 +1,266 @@
+package builder
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+
+	"github.com/johnfercher/maroto/v2/pkg/consts/border"
+	"github.com/johnfercher/maroto/v2/pkg/consts/linestyle"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+type (
+	// Theme is the look of generated documents: colours, font sizes,
+	// spacing, borders and the sections of each document type. Start from
+	// one of the built-in themes and change what differs, e.g.
+	//
+	//	theme := builder.DefaultTheme()
+	//	theme.Palette.Text = props.Color{Red: 0, Green: 80, Blue: 60}
+	//	cfg := builder.Config{Theme: &theme}
+	Theme struct {
+		Palette    Palette
+		Typography Typography
+		Spacing    Spacing
+		Border     Border
+
+		// Sections lists the sections of each document type in the order
+		// they are rendered below the page header. Document types without
+		// an entry have the sections of DefaultSections.
+		Sections map[DocumentType][]Section
+	}
+
+	// Palette is the colours of a theme.
+	Palette struct {
+		Text          props.Color
+		SecondaryText props.Color
+		Link          props.Color
+		Border        props.Color
+		// Paid and Overdue are the colours of invoice status stamps.
+		Paid    props.Color
+		Overdue props.Color
+	}
+
+	// Typography is the font size scale of a theme, in points.
+	Typography struct {
+		// Display is the amount of receipts.
+		Display float64
+		// Title is the title of receipts.
+		Title float64
+		// Headline is the issuer and title of invoices and statements.
+		Headline float64
+		// Heading is the section headings of statements.
+		Heading float64
+		// Subheading is the section headings of invoices and the text of
+		// statements.
+		Subheading float64
+		Body       float64
+		// Caption is secondary text such as item descriptions and notes.
+		Caption  float64
+		Footnote float64
+	}
+
+	// Spacing is the vertical spacing of a theme, in millimetres.
+	Spacing struct {
+		// Section is the space above section headings.
+		Section float64
+		// Line is the height of a line of text in tables.
+		Line float64
+		// Item is the space between detail items.
+		Item float64
+	}
+
+	// Border is the style of the lines separating headings and rows.
+	Border struct {
+		None      bool
+		Thickness float64
+		Style     linestyle.Type
+	}
+
+	// Section is a part of a document below the page header.
+	Section string
+)
+
+const (
+	SectionBillTo  Section = "bill_to"
+	SectionReason  Section = "reason"
+	SectionSummary Section = "summary"
+	SectionDetails Section = "details"
+	SectionPayment Section = "payment"
+	SectionNotes   Section = "notes"
+	SectionTerms   Section = "terms"
+	SectionPayer   Section = "payer"
+	SectionPayee   Section = "payee"
+	SectionChannel Section = "channel"
+	SectionAmount  Section = "amount"
+	SectionIssuer  Section = "issuer"
+)
+
+// DefaultSections are the sections of each document type, in order. Notes
+// and terms only appear when the params have some.
+var DefaultSections = map[DocumentType][]Section{
+	DocumentInvoice:          {SectionBillTo, SectionSummary, SectionDetails, SectionPayment, SectionNotes, SectionTerms},
+	DocumentQuote:            {SectionBillTo, SectionSummary, SectionDetails, SectionTerms},
+	DocumentCreditNote:       {SectionBillTo, SectionReason, SectionSummary, SectionDetails},
+	DocumentPaymentStatement: {SectionPayer, SectionPayee, SectionChannel, SectionSummary, SectionDetails, SectionNotes, SectionTerms},
+	DocumentReceipt:          {SectionAmount, SectionDetails, SectionIssuer},
+}
+
+// DefaultTheme returns the theme documents are rendered with when
+// Config.Theme is not set.
+func DefaultTheme() Theme {
+	return Theme{
+		Palette: Palette{
+			Text:          props.Color{Red: 50, Green: 50, Blue: 93},
+			SecondaryText: props.Color{Red: 80, Green: 80, Blue: 123},
+			Link:          props.Color{Red: 0, Green: 0, Blue: 255},
+			Border:        props.Color{Red: 200, Green: 200, Blue: 200},
+			Paid:          props.Color{Red: 0, Green: 128, Blue: 64},
+			Overdue:       props.Color{Red: 200, Green: 34, Blue: 34},
+		},
+		Typography: Typography{
+			Display:    20,
+			Title:      18,
+			Headline:   14,
+			Heading:    12,
+			Subheading: 10,
+			Body:       9,
+			Caption:    8,
+			Footnote:   7,
+		},
+		Spacing: Spacing{
+			Section: 8,
+			Line:    6,
+			Item:    2,
+		},
+	}
+}
+
+// MonochromeTheme returns a black and grey theme for printing, with thin
+// dashed separators.
+func MonochromeTheme() Theme {
+	theme := DefaultTheme()
+	theme.Palette = Palette{
+		Text:          props.Color{Red: 0, Green: 0, Blue: 0},
+		SecondaryText: props.Color{Red: 90, Green: 90, Blue: 90},
+		Link:          props.Color{Red: 0, Green: 0, Blue: 0},
+		Border:        props.Color{Red: 120, Green: 120, Blue: 120},
+		Paid:          props.Color{Red: 90, Green: 90, Blue: 90},
+		Overdue:       props.Color{Red: 0, Green: 0, Blue: 0},
+	}
+	theme.Border = Border{Thickness: 0.1, Style: linestyle.Dashed}
+	return theme
+}
+
+// CompactTheme returns a theme with smaller type and spacing, fitting more
+// detail items on a page.
+func CompactTheme() Theme {
+	theme := DefaultTheme()
+	theme.Typography = Typography{
+		Display:    16,
+		Title:      14,
+		Headline:   12,
+		Heading:    10,
+		Subheading: 9,
+		Body:       8,
+		Caption:    7,
+		Footnote:   6,
+	}
+	theme.Spacing = Spacing{
+		Section: 5,
+		Line:    5,
+		Item:    1,
+	}
+	return theme
+}
+
+// themes are the built-in themes by name.
+var themes = map[string]func() Theme{
+	"default":    DefaultTheme,
+	"monochrome": MonochromeTheme,
+	"compact":    CompactTheme,
+}
+
+// ThemeByName returns the built-in theme of the given name.
+func ThemeByName(name string) (Theme, error) {
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q", name)
+	}
+	return theme(), nil
+}
+
+// ThemeNames lists the names of the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// theme returns the theme of the builder.
+func (b *Builder) theme() *Theme {
+	if b.cfg.Theme == nil {
+		theme := DefaultTheme()
+		b.cfg.Theme = &theme
+	}
+	return b.cfg.Theme
+}
+
+// sections returns the sections of the document in the order of the theme.
+func (b *Builder) sections() []Section {
+	if sections, ok := b.theme().Sections[b.DocumentType()]; ok {
+		return sections
+	}
+	return DefaultSections[b.DocumentType()]
+}
+
+// borderStyle returns the style of rows underlined by a separator.
+func (b *Builder) borderStyle() *props.Cell {
+	return b.separatorStyle(border.Bottom)
+}
+
+// separatorStyle returns the style of rows with a separator on the given
+// side, nil when the theme has none.
+func (b *Builder) separatorStyle(side border.Type) *props.Cell {
+	theme := b.theme()
+	if theme.Border.None {
+		return nil
+	}
+	color := theme.Palette.Border
+	return &props.Cell{
+		BorderType:      side,
+		BorderColor:     &color,
+		BorderThickness: theme.Border.Thickness,
+		LineStyle:       theme.Border.Style,
+	}
+}
+
+// headingHeight is the height of the rows of section headings, which
+// leave Spacing.Section above the text.
+func (b *Builder) headingHeight() float64 {
+	return b.theme().Spacing.Section + 8
+}
+
+// css returns the palette and type scale of the theme as CSS custom
+// properties, for HTML documents.
+func (t *Theme) css() template.CSS {
+	rgb := func(c props.Color) string {
+		return fmt.Sprintf("rgb(%d, %d, %d)", c.Red, c.Green, c.Blue)
+	}
+	rule := "none"
+	if !t.Border.None {
+		style := "solid"
+		if t.Border.Style == linestyle.Dashed {
+			style = "dashed"
+		}
+		rule = fmt.Sprintf("1px %s %s", style, rgb(t.Palette.Border))
+	}
+	return template.CSS(fmt.Sprintf(
+		"--text: %s; --secondary-text: %s; --link: %s; --border: %s; --paid: %s; --overdue: %s; --rule: %s; --headline: %gpt; --subheading: %gpt; --caption: %gpt; --footnote: %gpt;",
+		rgb(t.Palette.Text), rgb(t.Palette.SecondaryText), rgb(t.Palette.Link), rgb(t.Palette.Border), rgb(t.Palette.Paid), rgb(t.Palette.Overdue), rule,
+		t.Typography.Headline, t.Typography.Subheading, t.Typography.Caption, t.Typography.Footnote,
+	))
+}
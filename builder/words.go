@@ -0,0 +1,51 @@
+package builder
+
+import (
+	"log"
+
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	marotoCore "github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/quail-ink/bizdocgen/i18n"
+	"github.com/shopspring/decimal"
+)
+
+// amountInWords returns the amount written out in words in the document
+// language when Config.AmountInWords is set, "" otherwise.
+func (b *Builder) amountInWords(amount decimal.Decimal, currency string) string {
+	if !b.cfg.AmountInWords {
+		return ""
+	}
+	words, err := i18n.AmountInWords(b.cfg.Lang, amount, currency, b.cfg.Numerals)
+	if err != nil {
+		log.Printf("failed to write amount in words: %v\n", err)
+		return ""
+	}
+	return words
+}
+
+// BuildAmountInWordsRows returns the rows of the amount written out in
+// words under a total, wrapped to the page width, none unless
+// Config.AmountInWords is set.
+func (b *Builder) BuildAmountInWordsRows(amount decimal.Decimal, currency string, alignment align.Type) []marotoCore.Row {
+	words := b.amountInWords(amount, currency)
+	if words == "" {
+		return nil
+	}
+	size := b.theme().Typography.Body
+	m := b.measurer()
+	rows := []marotoCore.Row{}
+	// maroto wraps text reaching the width of its column, leave it a margin
+	for _, line := range m.wrap([]markupSpan{{Text: words}}, m.width-1, size) {
+		s := ""
+		for _, span := range line {
+			s += span.Text
+		}
+		rows = append(rows, row.New(size*0.5).Add(
+			text.NewCol(12, s, props.Text{Size: size, Align: alignment, Color: b.fgColor}),
+		))
+	}
+	return append(rows, row.New(b.theme().Spacing.Item))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/quail-ink/bizdocgen/builder"
+)
+
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	cfg := configFlags(fs)
+	docType := fs.String("type", string(builder.DocumentInvoice), "document type (invoice, statement, quote, receipt, creditnote)")
+	outputDir := fs.String("o", ".", "output directory")
+	workers := fs.Int("workers", 0, "number of documents rendered concurrently (defaults to the number of CPUs)")
+	name := fs.String("name", builder.DefaultBatchFilenameTemplate, "output file name template, e.g. '{{.Date.Format \"20060102\"}}-{{.Party}}.pdf'")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bizdocgen batch [flags] <dir|glob|params.yaml>...")
+		fs.PrintDefaults()
+		return exitUsage
+	}
+
+	sources, err := builder.CollectBatchSources(fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+		return exitInvalid
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := builder.GenerateBatch(ctx, sources, builder.BatchOptions{
+		Type:             builder.DocumentType(*docType),
+		Config:           *cfg,
+		Workers:          *workers,
+		OutputDir:        *outputDir,
+		FilenameTemplate: *name,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, result := range report.Results {
+		if result.Err == nil {
+			fmt.Printf("ok\t%s\t%s\n", result.Source, result.Output)
+			continue
+		}
+		fmt.Printf("FAIL\t%s\t%s: %v\n", result.Source, result.Stage, result.Err)
+		switch result.Stage {
+		case builder.BatchStageRender:
+			code = exitRender
+		case builder.BatchStageLoad, builder.BatchStageValidate:
+			if code != exitRender {
+				code = exitInvalid
+			}
+		default:
+			if code == exitOK {
+				code = exitFailure
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr, "bizdocgen: %d documents, %d failed\n", len(report.Results), len(report.Failed()))
+	return code
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/builder"
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/quail-ink/bizdocgen/einvoice"
+	"github.com/quail-ink/bizdocgen/i18n"
+	"github.com/quail-ink/bizdocgen/pdfa"
+)
+
+// configFlags registers the flags mirroring builder.Config.
+func configFlags(fs *flag.FlagSet) *builder.Config {
+	cfg := &builder.Config{}
+	fs.StringVar(&cfg.FontName, "font-name", "", "name of the custom font family")
+	fs.StringVar(&cfg.FontNormal, "font-normal", "", "path to the regular TTF font")
+	fs.StringVar(&cfg.FontItalic, "font-italic", "", "path to the italic TTF font")
+	fs.StringVar(&cfg.FontBold, "font-bold", "", "path to the bold TTF font")
+	fs.StringVar(&cfg.FontBoldItalic, "font-bold-italic", "", "path to the bold italic TTF font")
+	fs.StringVar(&cfg.Lang, "lang", "en", "document language (en, ja)")
+	fs.StringVar(&cfg.Metadata.Title, "title", "", "PDF title (defaults to the document type, ID and issuer)")
+	fs.StringVar(&cfg.Metadata.Author, "author", "", "PDF author (defaults to the issuer)")
+	fs.StringVar(&cfg.Metadata.Subject, "subject", "", "PDF subject")
+	fs.StringVar(&cfg.Metadata.Keywords, "keywords", "", "PDF keywords, comma separated")
+	fs.Func("footer", "markdown-lite file rendered at the bottom of every page", func(filename string) error {
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		cfg.Footer = map[string]string{"": string(buf)}
+		return nil
+	})
+	fs.Func("theme", "built-in theme ("+strings.Join(builder.ThemeNames(), ", ")+")", func(name string) error {
+		theme, err := builder.ThemeByName(name)
+		if err != nil {
+			return err
+		}
+		cfg.Theme = &theme
+		return nil
+	})
+	fs.Func("seal-stamp", "stamp the issuer name when params have no seal (circle, square)", func(shape string) error {
+		cfg.Seal.Stamp = builder.StampShape(shape)
+		if cfg.Seal.Stamp != builder.StampCircle && cfg.Seal.Stamp != builder.StampSquare {
+			return fmt.Errorf("unknown stamp shape %q", shape)
+		}
+		return nil
+	})
+	fs.BoolVar(&cfg.AmountInWords, "amount-in-words", false, "write the total out in words under the summary")
+	fs.Func("numerals", "numerals of Japanese amounts in words (kanji, daiji)", func(numerals string) error {
+		cfg.Numerals = i18n.Numerals(numerals)
+		if cfg.Numerals != i18n.NumeralsKanji && cfg.Numerals != i18n.NumeralsDaiji {
+			return fmt.Errorf("unknown numerals %q", numerals)
+		}
+		return nil
+	})
+	fs.Func("holidays", "file of holidays due dates are moved off, one 2006-01-02 date per line", func(filename string) error {
+		holidays, err := readHolidays(filename)
+		if err != nil {
+			return err
+		}
+		cfg.Holidays = holidays
+		return nil
+	})
+	fs.Func("pdfa", "produce PDF/A of the level (2b, 3b), requires custom fonts", func(level string) error {
+		cfg.PDFA = pdfa.Level(level)
+		if cfg.PDFA.Part() == 0 {
+			return fmt.Errorf("unknown PDF/A level %q", level)
+		}
+		return nil
+	})
+	return cfg
+}
+
+// readHolidays reads a file of dates, one per line in the 2006-01-02 or
+// 2006/01/02 layout, skipping blank lines and # comments.
+func readHolidays(filename string) ([]time.Time, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var holidays []time.Time
+	for ix, line := range strings.Split(string(buf), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", strings.ReplaceAll(line, "/", "-"))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filename, ix+1, err)
+		}
+		holidays = append(holidays, date)
+	}
+	return holidays, nil
+}
+
+func documentCommand(docType builder.DocumentType) func(args []string) int {
+	return func(args []string) int {
+		fs := flag.NewFlagSet(string(docType), flag.ContinueOnError)
+		cfg := configFlags(fs)
+		output := fs.String("o", "", "output path (defaults to the params file name with the extension of the format)")
+		format := fs.String("format", "pdf", "output format (pdf, html)")
+		force := fs.Bool("force", false, "render even when validation reports errors")
+		var facturX *string
+		if docType == builder.DocumentInvoice {
+			facturX = fs.String("facturx", "", "embed Factur-X XML of the profile (minimum, basic, en16931), requires custom fonts")
+		}
+		if err := fs.Parse(args); err != nil {
+			return exitUsage
+		}
+		if facturX != nil {
+			cfg.FacturX = einvoice.CIIProfile(*facturX)
+		}
+		if fs.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "Usage: bizdocgen %s [flags] <params.yaml>\n", docType)
+			fs.PrintDefaults()
+			return exitUsage
+		}
+		filename := fs.Arg(0)
+		if *format != "pdf" && *format != "html" {
+			fmt.Fprintf(os.Stderr, "bizdocgen: unknown format %q\n", *format)
+			return exitUsage
+		}
+
+		b, err := builder.NewBuilderFromFile(docType, *cfg, filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitInvalid
+		}
+
+		report := b.Validate()
+		printReport(filename, report)
+		if !report.OK() && !*force {
+			return exitInvalid
+		}
+
+		generate := b.Generate
+		if *format == "html" {
+			generate = b.GenerateHTML
+		}
+		buf, err := generate()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: failed to render %s: %v\n", filename, err)
+			return exitRender
+		}
+
+		out := *output
+		if out == "" {
+			out = strings.TrimSuffix(filename, filepath.Ext(filename)) + "." + *format
+		}
+		if err := os.WriteFile(out, buf, 0666); err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitFailure
+		}
+		fmt.Fprintf(os.Stderr, "bizdocgen: wrote %s\n", out)
+		return exitOK
+	}
+}
+
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	docType := fs.String("type", string(builder.DocumentInvoice), "document type (invoice, statement, quote, receipt, creditnote)")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bizdocgen validate [-type invoice] <params.yaml>...")
+		fs.PrintDefaults()
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, filename := range fs.Args() {
+		b, err := builder.NewBuilderFromFile(builder.DocumentType(*docType), builder.Config{}, filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			code = exitInvalid
+			continue
+		}
+		report := b.Validate()
+		printReport(filename, report)
+		if !report.OK() {
+			code = exitInvalid
+		}
+	}
+	return code
+}
+
+func printReport(filename string, report *core.ValidationReport) {
+	for _, err := range report.Errors {
+		fmt.Fprintf(os.Stderr, "%s: error: %v\n", filename, err)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(os.Stderr, "%s: warning: %v\n", filename, warning)
+	}
+}
+
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bizdocgen verify <document.pdf>...")
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, filename := range fs.Args() {
+		report, err := verifyPDFA(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			code = exitInvalid
+			continue
+		}
+		for _, problem := range report.Problems {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", filename, problem)
+		}
+		if !report.OK() {
+			code = exitInvalid
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: PDF/A-%d%s\n", filename, report.Part, strings.ToLower(report.Conformance))
+	}
+	return code
+}
+
+func verifyPDFA(filename string) (*pdfa.Report, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return pdfa.Verify(f)
+}
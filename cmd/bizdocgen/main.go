@@ -0,0 +1,83 @@
+// Command bizdocgen generates business documents from parameter files.
+//
+// Usage:
+//
+//	bizdocgen <command> [flags] <params.yaml>
+//
+// Run "bizdocgen help" for the list of commands.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	exitOK = 0
+	// exitFailure is returned for unexpected failures such as unwritable
+	// output files.
+	exitFailure = 1
+	exitUsage   = 2
+	// exitInvalid is returned when params cannot be loaded or fail
+	// validation.
+	exitInvalid = 3
+	// exitRender is returned when a document cannot be rendered.
+	exitRender = 4
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"invoice", "generate an invoice PDF", documentCommand("invoice")},
+		{"statement", "generate a payment statement PDF", documentCommand("statement")},
+		{"quote", "generate a quotation PDF", documentCommand("quote")},
+		{"receipt", "generate a receipt PDF", documentCommand("receipt")},
+		{"creditnote", "generate a credit note PDF", documentCommand("creditnote")},
+		{"batch", "generate many documents concurrently", runBatch},
+		{"validate", "check params without rendering", runValidate},
+		{"verify", "check PDFs for PDF/A conformance basics", runVerify},
+		{"ubl", "export an invoice as UBL 2.1 e-invoice XML", runUBL},
+		{"schema", "print the JSON Schema of params", runSchema},
+		{"serve", "serve documents over HTTP", runServe},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "bizdocgen: unknown command %q\n\n", args[0])
+	usage()
+	return exitUsage
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: bizdocgen <command> [flags] <params.yaml>")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Exit codes: 0 success, 1 failure, 2 usage, 3 invalid params, 4 rendering failure")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/quail-ink/bizdocgen/builder"
+)
+
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	docType := fs.String("type", string(builder.DocumentInvoice), "document type (invoice, statement, quote, receipt, creditnote)")
+	outputDir := fs.String("o", "", "write the schemas of every document type to <dir>/<type>.schema.json instead")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bizdocgen schema [flags]")
+		fs.PrintDefaults()
+		return exitUsage
+	}
+
+	if *outputDir == "" {
+		buf, err := marshalSchema(builder.DocumentType(*docType))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitUsage
+		}
+		os.Stdout.Write(buf)
+		return exitOK
+	}
+
+	if err := os.MkdirAll(*outputDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+		return exitFailure
+	}
+	for _, t := range builder.DocumentTypes {
+		buf, err := marshalSchema(t)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitFailure
+		}
+		if err := os.WriteFile(filepath.Join(*outputDir, string(t)+".schema.json"), buf, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitFailure
+		}
+	}
+	return exitOK
+}
+
+func marshalSchema(docType builder.DocumentType) ([]byte, error) {
+	schema, err := builder.JSONSchema(docType)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/builder"
+	"github.com/quail-ink/bizdocgen/i18n"
+	"github.com/quail-ink/bizdocgen/pdfa"
+	"github.com/quail-ink/bizdocgen/server"
+	"gopkg.in/yaml.v3"
+)
+
+// fontProfile is an entry of the -profiles file.
+type fontProfile struct {
+	FontName       string `yaml:"font_name"`
+	FontNormal     string `yaml:"font_normal"`
+	FontItalic     string `yaml:"font_italic"`
+	FontBold       string `yaml:"font_bold"`
+	FontBoldItalic string `yaml:"font_bold_italic"`
+	Lang           string `yaml:"lang"`
+	PDFA           string `yaml:"pdfa"`
+	Theme          string `yaml:"theme"`
+	// Holidays is a file of holidays, as read by the -holidays flag.
+	Holidays      string `yaml:"holidays"`
+	AmountInWords bool   `yaml:"amount_in_words"`
+	Numerals      string `yaml:"numerals"`
+	// Footer maps languages to footers, "" being the default one.
+	Footer map[string]string `yaml:"footer"`
+}
+
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	cfg := configFlags(fs)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	profilesFile := fs.String("profiles", "", "YAML file mapping font profile names to font files")
+	sealDir := fs.String("seal-dir", "", "directory of seal and logo images, referenced by file name in params")
+	maxBody := fs.Int64("max-body", server.DefaultMaxBodyBytes, "maximum size of request params in bytes")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: bizdocgen serve [flags]")
+		fs.PrintDefaults()
+		return exitUsage
+	}
+
+	profiles := map[string]builder.Config{server.DefaultProfile: *cfg}
+	if *profilesFile != "" {
+		if err := readProfiles(*profilesFile, profiles); err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitInvalid
+		}
+	}
+	for name, profile := range profiles {
+		fonts, err := builder.LoadFonts(profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: failed to load fonts of profile %q: %v\n", name, err)
+			return exitInvalid
+		}
+		profile.CustomFonts = fonts
+		profiles[name] = profile
+	}
+
+	seals := map[string][]byte{}
+	if *sealDir != "" {
+		var err error
+		if seals, err = readSeals(*sealDir); err != nil {
+			fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+			return exitInvalid
+		}
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: server.New(server.Options{
+			Profiles:     profiles,
+			Seals:        seals,
+			MaxBodyBytes: *maxBody,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// readProfiles adds the font profiles defined in filename to profiles.
+func readProfiles(filename string, profiles map[string]builder.Config) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	defs := map[string]fontProfile{}
+	if err := yaml.Unmarshal(data, &defs); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	for name, def := range defs {
+		var theme *builder.Theme
+		if def.Theme != "" {
+			t, err := builder.ThemeByName(def.Theme)
+			if err != nil {
+				return fmt.Errorf("%s: profile %q: %w", filename, name, err)
+			}
+			theme = &t
+		}
+		switch i18n.Numerals(def.Numerals) {
+		case "", i18n.NumeralsKanji, i18n.NumeralsDaiji:
+		default:
+			return fmt.Errorf("%s: profile %q: unknown numerals %q", filename, name, def.Numerals)
+		}
+		var holidays []time.Time
+		if def.Holidays != "" {
+			if holidays, err = readHolidays(def.Holidays); err != nil {
+				return fmt.Errorf("%s: profile %q: %w", filename, name, err)
+			}
+		}
+		profiles[name] = builder.Config{
+			FontName:       def.FontName,
+			FontNormal:     def.FontNormal,
+			FontItalic:     def.FontItalic,
+			FontBold:       def.FontBold,
+			FontBoldItalic: def.FontBoldItalic,
+			Lang:           def.Lang,
+			PDFA:           pdfa.Level(def.PDFA),
+			Footer:         def.Footer,
+			Theme:          theme,
+			Holidays:       holidays,
+			AmountInWords:  def.AmountInWords,
+			Numerals:       i18n.Numerals(def.Numerals),
+		}
+	}
+	return nil
+}
+
+// readSeals reads every file in dir, keyed by its file name.
+func readSeals(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	seals := map[string][]byte{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		buf, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		seals[entry.Name()] = buf
+	}
+	return seals, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/quail-ink/bizdocgen/einvoice"
+)
+
+func runUBL(args []string) int {
+	fs := flag.NewFlagSet("ubl", flag.ContinueOnError)
+	profile := fs.String("profile", string(einvoice.ProfilePeppolBIS), "e-invoice profile (peppol, jp-pint)")
+	output := fs.String("o", "", "output XML path (defaults to the params file name with a .xml extension)")
+	check := fs.Bool("check", false, "only report missing business terms")
+	var holidays []time.Time
+	fs.Func("holidays", "file of holidays due dates are moved off, one 2006-01-02 date per line", func(filename string) error {
+		var err error
+		holidays, err = readHolidays(filename)
+		return err
+	})
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: bizdocgen ubl [flags] <invoice.yaml>")
+		fs.PrintDefaults()
+		return exitUsage
+	}
+	filename := fs.Arg(0)
+
+	params := &core.InvoiceParams{}
+	if err := params.Load(filename); err != nil {
+		fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+		return exitInvalid
+	}
+
+	params.DueDate = params.ComputeDueDate(holidays)
+
+	report := einvoice.Validate(params, einvoice.Profile(*profile))
+	printReport(filename, report)
+	if !report.OK() {
+		return exitInvalid
+	}
+	if *check {
+		return exitOK
+	}
+
+	buf, err := einvoice.Marshal(params, einvoice.Profile(*profile))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bizdocgen: failed to export %s: %v\n", filename, err)
+		return exitRender
+	}
+
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".xml"
+	}
+	if err := os.WriteFile(out, buf, 0666); err != nil {
+		fmt.Fprintf(os.Stderr, "bizdocgen: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintf(os.Stderr, "bizdocgen: wrote %s\n", out)
+	return exitOK
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	CreditNoteParams struct {
+		ID           string    `yaml:"id" json:"id"`
+		TaxNumber    string    `yaml:"tax_number" json:"tax_number"`
+		Date         time.Time `yaml:"date" json:"date" time_format:"2006/01/02"`
+		Currency     string    `yaml:"currency" json:"currency"`
+		CompanyName  string    `yaml:"company_name" json:"company_name"`
+		CompanyAddr  string    `yaml:"company_address" json:"company_address"`
+		CompanyEmail string    `yaml:"company_email" json:"company_email"`
+		CompanySeal  string    `yaml:"company_seal" json:"company_seal"`
+		CompanyLogo  string    `yaml:"company_logo" json:"company_logo"`
+
+		BillToCompany string `yaml:"bill_to_company" json:"bill_to_company"`
+		BillToAddress string `yaml:"bill_to_address" json:"bill_to_address"`
+
+		// Invoice being refunded or corrected
+		OriginalInvoiceID   string    `yaml:"original_invoice_id" json:"original_invoice_id"`
+		OriginalInvoiceDate time.Time `yaml:"original_invoice_date" json:"original_invoice_date" time_format:"2006/01/02"`
+		Reason              string    `yaml:"reason" json:"reason"`
+
+		// Summary
+		Summary InvoiceSummary `yaml:"summary" json:"summary"`
+
+		// Details, carrying negative amounts for reversed lines
+		DetailItems []InvoiceDetailItem `yaml:"detail_items" json:"detail_items"`
+	}
+)
+
+// Load decodes the params from a YAML or JSON file.
+func (params *CreditNoteParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadBytes decodes the params from YAML or JSON data.
+func (params *CreditNoteParams) LoadBytes(data []byte) error {
+	return decode("", data, params)
+}
+
+// LoadReader decodes the params from YAML or JSON read from r.
+func (params *CreditNoteParams) LoadReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// NewCreditNoteFromInvoice creates a credit note reversing the detail items
+// of the original invoice at the given indexes, or all of them when no index
+// is given. The summary of the credit note is computed from the reversed
+// items.
+func NewCreditNoteFromInvoice(original *InvoiceParams, id string, date time.Time, reason string, lines ...int) (*CreditNoteParams, error) {
+	if len(lines) == 0 {
+		for ix := range original.DetailItems {
+			lines = append(lines, ix)
+		}
+	}
+
+	items := make([]InvoiceDetailItem, 0, len(lines))
+	for _, ix := range lines {
+		if ix < 0 || ix >= len(original.DetailItems) {
+			return nil, fmt.Errorf("detail item %d does not exist in invoice %s", ix, original.ID)
+		}
+		items = append(items, original.DetailItems[ix].Reverse())
+	}
+
+	summary := original.Summary
+	summary.TotalExcludeTax = decimal.Zero
+	summary.TotalIncludeTax = decimal.Zero
+	summary.Tax = decimal.Zero
+	summary.Compute = true
+
+	return &CreditNoteParams{
+		ID:                  id,
+		TaxNumber:           original.TaxNumber,
+		Date:                date,
+		Currency:            original.Currency,
+		CompanyName:         original.CompanyName,
+		CompanyAddr:         original.CompanyAddr,
+		CompanyEmail:        original.CompanyEmail,
+		CompanySeal:         original.CompanySeal,
+		CompanyLogo:         original.CompanyLogo,
+		BillToCompany:       original.BillToCompany,
+		BillToAddress:       original.BillToAddress,
+		OriginalInvoiceID:   original.ID,
+		OriginalInvoiceDate: original.Date,
+		Reason:              reason,
+		Summary:             summary,
+		DetailItems:         items,
+	}, nil
+}
+
+// ToInvoiceParams returns an invoice view of the credit note used to render
+// the invoice sections.
+func (params *CreditNoteParams) ToInvoiceParams() *InvoiceParams {
+	return &InvoiceParams{
+		ID:            params.ID,
+		TaxNumber:     params.TaxNumber,
+		Date:          params.Date,
+		Currency:      params.Currency,
+		CompanyName:   params.CompanyName,
+		CompanyAddr:   params.CompanyAddr,
+		CompanyEmail:  params.CompanyEmail,
+		CompanySeal:   params.CompanySeal,
+		CompanyLogo:   params.CompanyLogo,
+		BillToCompany: params.BillToCompany,
+		BillToAddress: params.BillToAddress,
+		Summary:       params.Summary,
+		DetailItems:   params.DetailItems,
+		Payment:       InvoicePayment{Disabled: true},
+	}
+}
@@ -0,0 +1,44 @@
+package core
+
+// knownCurrencies lists the ISO 4217 codes accepted in params, plus the
+// "円" spelling of the yen used in Japanese documents. Amounts are rounded
+// to two decimals except in yen, so other currencies without minor units
+// are not accepted.
+var knownCurrencies = map[string]bool{
+	"AED": true, "AUD": true, "BRL": true, "CAD": true, "CHF": true,
+	"CNY": true, "CZK": true, "DKK": true, "EUR": true, "GBP": true,
+	"HKD": true, "IDR": true, "ILS": true, "INR": true, "JPY": true,
+	"MXN": true, "MYR": true, "NOK": true, "NZD": true, "PHP": true,
+	"PLN": true, "SEK": true, "SGD": true, "THB": true, "TRY": true,
+	"TWD": true, "USD": true, "ZAR": true,
+	"円": true,
+}
+
+// IsKnownCurrency reports whether the currency is one bizdocgen knows how
+// to format.
+func IsKnownCurrency(currency string) bool {
+	return knownCurrencies[currency]
+}
+
+// IsJPY reports whether the currency is the Japanese yen.
+func IsJPY(currency string) bool {
+	return currency == "JPY" || currency == "円"
+}
+
+// CurrencyPlaces returns the number of decimal places amounts in the
+// currency are rounded to.
+func CurrencyPlaces(currency string) int32 {
+	if IsJPY(currency) {
+		return 0
+	}
+	return 2
+}
+
+// ISOCurrency returns the ISO 4217 code of the currency, mapping "円" to
+// "JPY".
+func ISOCurrency(currency string) string {
+	if IsJPY(currency) {
+		return "JPY"
+	}
+	return currency
+}
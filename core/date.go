@@ -0,0 +1,90 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DateLayout is the date layout used by params when a field has no
+// time_format tag.
+const DateLayout = "2006/01/02"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ParseDate parses a params date in the given layout, ISO 8601 (2006-01-02)
+// or RFC 3339 form.
+func ParseDate(value, layout string) (time.Time, error) {
+	if layout == "" {
+		layout = DateLayout
+	}
+	for _, l := range []string{layout, time.DateOnly, time.RFC3339Nano} {
+		if t, err := time.Parse(l, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as a date, expected %s or %s", value, layout, time.DateOnly)
+}
+
+// normalizeDates rewrites the date values of a document decoded into typ to
+// RFC 3339 timestamps, honoring the time_format tag of each field, so YAML
+// and JSON params accept the same date forms.
+func normalizeDates(n *yaml.Node, typ reflect.Type, layout, path string) FieldErrors {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	errs := FieldErrors{}
+	switch {
+	case typ == timeType:
+		if n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+			return errs
+		}
+		if n.Value == "" {
+			n.Tag, n.Style = "!!null", 0
+			return errs
+		}
+		t, err := ParseDate(n.Value, layout)
+		if err != nil {
+			return append(errs, &FieldError{Path: path, Line: n.Line, Column: n.Column, Message: err.Error()})
+		}
+		n.Value, n.Tag, n.Style = t.Format(time.RFC3339Nano), "!!timestamp", 0
+	case typ.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		for ix := 0; ix+1 < len(n.Content); ix += 2 {
+			key, value := n.Content[ix], n.Content[ix+1]
+			field, ok := fieldByYAMLName(typ, key.Value)
+			if !ok {
+				continue
+			}
+			errs = append(errs, normalizeDates(value, field.Type, field.Tag.Get("time_format"), joinPath(path, key.Value))...)
+		}
+	case typ.Kind() == reflect.Slice && n.Kind == yaml.SequenceNode:
+		for ix, item := range n.Content {
+			errs = append(errs, normalizeDates(item, typ.Elem(), layout, fmt.Sprintf("%s[%d]", path, ix))...)
+		}
+	}
+	return errs
+}
+
+func fieldByYAMLName(typ reflect.Type, name string) (reflect.StructField, bool) {
+	for ix := 0; ix < typ.NumField(); ix++ {
+		field := typ.Field(ix)
+		if tagName(field.Tag.Get("yaml"), field.Name) == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// tagName returns the key a struct tag assigns to a field, defaulting to the
+// lowercased field name like the YAML decoder.
+func tagName(tag, fieldName string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return strings.ToLower(fieldName)
+	}
+	return name
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrFileNotFound is returned when a params file does not exist.
+var ErrFileNotFound = errors.New("params file not found")
+
+type (
+	// ParseError reports params data that is not well-formed, at the line
+	// the YAML decoder reports.
+	ParseError struct {
+		Filename string
+		Line     int
+		Message  string
+	}
+
+	// FieldError reports a field that could not be decoded or failed
+	// validation, addressed by its YAML path, e.g. "detail_items[1].amount",
+	// or "." for the document as a whole.
+	FieldError struct {
+		Path    string `json:"path"`
+		Line    int    `json:"line,omitempty"`
+		Column  int    `json:"column,omitempty"`
+		Message string `json:"message"`
+	}
+
+	// FieldErrors collects every FieldError found in one document.
+	FieldErrors []*FieldError
+)
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	if e.Filename != "" {
+		sb.WriteString(e.Filename)
+		sb.WriteString(": ")
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "line %d: ", e.Line)
+	}
+	sb.WriteString(e.Message)
+	return sb.String()
+}
+
+func (e *FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s (line %d, column %d): %s", e.Path, e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+func (errs FieldErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for ix, err := range errs {
+		msgs[ix] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
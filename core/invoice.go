@@ -1,84 +1,173 @@
 package core
 
 import (
-	"os"
+	"io"
 	"time"
 
 	"github.com/shopspring/decimal"
-	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 )
 
 type (
 	InvoiceDetailItem struct {
-		Date            time.Time       `yaml:"date" time_format:"2006/01/02"`
-		Title           string          `yaml:"title"`
-		Desc            string          `yaml:"desc"`
-		URL             string          `yaml:"url"`
-		URLs            []string        `yaml:"urls"`
-		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax"`
-		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax"`
-		Tax             decimal.Decimal `yaml:"tax"`
+		Date            time.Time       `yaml:"date" json:"date" time_format:"2006/01/02"`
+		Title           string          `yaml:"title" json:"title"`
+		Desc            string          `yaml:"desc" json:"desc"`
+		URL             string          `yaml:"url" json:"url"`
+		URLs            []string        `yaml:"urls" json:"urls"`
+		Quantity        decimal.Decimal `yaml:"quantity" json:"quantity"`
+		Unit            string          `yaml:"unit" json:"unit"`
+		UnitPrice       decimal.Decimal `yaml:"unit_price" json:"unit_price"`
+		Discount        decimal.Decimal `yaml:"discount" json:"discount"`
+		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax" json:"total_exclude_tax"`
+		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax" json:"total_include_tax"`
+		Tax             decimal.Decimal `yaml:"tax" json:"tax"`
+		TaxRate         decimal.Decimal `yaml:"tax_rate" json:"tax_rate"`
+		TaxCategory     string          `yaml:"tax_category" json:"tax_category"`
 	}
 
 	InvoiceSummary struct {
-		PeriodStart     time.Time       `yaml:"period_start" time_format:"2006/01/02"`
-		PeriodEnd       time.Time       `yaml:"period_end" time_format:"2006/01/02"`
-		Title           string          `yaml:"title"`
-		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax"`
-		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax"`
-		Tax             decimal.Decimal `yaml:"tax"`
-		TaxRate         decimal.Decimal `yaml:"tax_rate"`
+		PeriodStart     time.Time       `yaml:"period_start" json:"period_start" time_format:"2006/01/02"`
+		PeriodEnd       time.Time       `yaml:"period_end" json:"period_end" time_format:"2006/01/02"`
+		Title           string          `yaml:"title" json:"title"`
+		TotalExcludeTax decimal.Decimal `yaml:"total_exclude_tax" json:"total_exclude_tax"`
+		TotalIncludeTax decimal.Decimal `yaml:"total_include_tax" json:"total_include_tax"`
+		Tax             decimal.Decimal `yaml:"tax" json:"tax"`
+		TaxRate         decimal.Decimal `yaml:"tax_rate" json:"tax_rate"`
+
+		// Compute derives the totals from the detail items instead of
+		// trusting the ones typed above.
+		Compute  bool   `yaml:"compute" json:"compute"`
+		Rounding string `yaml:"rounding" json:"rounding"`
 	}
 
 	InvoicePayment struct {
-		Disabled              bool   `yaml:"show"`
-		PaymentID             string `yaml:"payment_id"`
-		Method                string `yaml:"method"`
-		ReceiveAccountBank    string `yaml:"receive_account_bank"`
-		ReceiveAccountBranch  string `yaml:"receive_account_branch"`
-		ReceiveDepositType    string `yaml:"receive_deposit_type"`
-		ReceiveAccountNumber  string `yaml:"receive_account_number"`
-		ReceiveAccountName    string `yaml:"receive_account_name"`
-		ReceiveAccountRouting string `yaml:"receive_account_routing"`
-		ReceiveAccountSwift   string `yaml:"receive_account_swift"`
+		Disabled              bool   `yaml:"show" json:"show"`
+		PaymentID             string `yaml:"payment_id" json:"payment_id"`
+		Method                string `yaml:"method" json:"method"`
+		ReceiveAccountBank    string `yaml:"receive_account_bank" json:"receive_account_bank"`
+		ReceiveAccountBranch  string `yaml:"receive_account_branch" json:"receive_account_branch"`
+		ReceiveDepositType    string `yaml:"receive_deposit_type" json:"receive_deposit_type"`
+		ReceiveAccountNumber  string `yaml:"receive_account_number" json:"receive_account_number"`
+		ReceiveAccountName    string `yaml:"receive_account_name" json:"receive_account_name"`
+		ReceiveAccountRouting string `yaml:"receive_account_routing" json:"receive_account_routing"`
+		ReceiveAccountSwift   string `yaml:"receive_account_swift" json:"receive_account_swift"`
 	}
 
 	InvoiceParams struct {
-		ID           string    `yaml:"id"`
-		TaxNumber    string    `yaml:"tax_number"`
-		Date         time.Time `yaml:"date" time_format:"2006/01/02"`
-		Currency     string    `yaml:"currency"`
-		CompanyName  string    `yaml:"company_name"`
-		CompanyAddr  string    `yaml:"company_address"`
-		CompanyEmail string    `yaml:"company_email"`
-		CompanySeal  string    `yaml:"company_seal"`
-
-		BillToCompany string `yaml:"bill_to_company"`
-		BillToAddress string `yaml:"bill_to_address"`
+		ID           string    `yaml:"id" json:"id"`
+		TaxNumber    string    `yaml:"tax_number" json:"tax_number"`
+		Date         time.Time `yaml:"date" json:"date" time_format:"2006/01/02"`
+		Currency     string    `yaml:"currency" json:"currency"`
+		CompanyName  string    `yaml:"company_name" json:"company_name"`
+		CompanyAddr  string    `yaml:"company_address" json:"company_address"`
+		CompanyEmail string    `yaml:"company_email" json:"company_email"`
+		CompanySeal  string    `yaml:"company_seal" json:"company_seal"`
+		CompanyLogo  string    `yaml:"company_logo" json:"company_logo"`
+
+		BillToCompany string `yaml:"bill_to_company" json:"bill_to_company"`
+		BillToAddress string `yaml:"bill_to_address" json:"bill_to_address"`
+
+		// Parties as structured e-invoices require them: ISO 3166-1 alpha-2
+		// country codes and Peppol endpoints of the form "scheme:identifier",
+		// e.g. "0188:1234567890123".
+		CompanyCountry  string `yaml:"company_country" json:"company_country"`
+		CompanyEndpoint string `yaml:"company_endpoint" json:"company_endpoint"`
+		BillToCountry   string `yaml:"bill_to_country" json:"bill_to_country"`
+		BillToTaxNumber string `yaml:"bill_to_tax_number" json:"bill_to_tax_number"`
+		BillToEndpoint  string `yaml:"bill_to_endpoint" json:"bill_to_endpoint"`
+
+		// BuyerReference (BT-10) and OrderReference (BT-13) are the
+		// references of the buyer and of its purchase order, Peppol
+		// requiring one of them.
+		BuyerReference string `yaml:"buyer_reference" json:"buyer_reference"`
+		OrderReference string `yaml:"order_reference" json:"order_reference"`
+
+		// QualifiedInvoice lays the invoice out as a Japanese qualified
+		// invoice (適格請求書).
+		QualifiedInvoice bool `yaml:"qualified_invoice" json:"qualified_invoice"`
 
 		// Summary
-		Summary InvoiceSummary `yaml:"summary"`
+		Summary InvoiceSummary `yaml:"summary" json:"summary"`
 
 		// Details
-		DetailItems []InvoiceDetailItem `yaml:"detail_items"`
+		DetailItems []InvoiceDetailItem `yaml:"detail_items" json:"detail_items"`
 
 		// Payment Instructions
-		Payment InvoicePayment `yaml:"payment"`
+		Payment InvoicePayment `yaml:"payment" json:"payment"`
+
+		// Payment terms and due date. DueDate is computed from the issue
+		// date and PaymentTerms, such as "net-30" or "eom+1", when it is
+		// not set, and moved off weekends and holidays by BusinessDay,
+		// "following" or "preceding".
+		DueDate      time.Time `yaml:"due_date" json:"due_date" time_format:"2006/01/02"`
+		PaymentTerms string    `yaml:"payment_terms" json:"payment_terms"`
+		BusinessDay  string    `yaml:"business_day" json:"business_day"`
+
+		// Status stamps the invoice as "paid" or "overdue".
+		Status string `yaml:"status" json:"status"`
+
+		// Notes, such as thanks, and terms, such as the payment terms and
+		// late fee policy, as markdown-lite text: paragraphs separated by
+		// blank lines, "- " bullets and **bold** spans.
+		Notes string `yaml:"notes" json:"notes"`
+		Terms string `yaml:"terms" json:"terms"`
 	}
 )
 
-func (params *InvoiceParams) Load(filename string) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		logrus.WithError(err).Fatalf("failed to read YAML file")
-		return err
+// IsItemized reports whether the item is priced by quantity and unit price
+// rather than by pre-computed totals.
+func (item *InvoiceDetailItem) IsItemized() bool {
+	return !item.UnitPrice.IsZero()
+}
+
+// Qty returns the item quantity, defaulting to 1 when it is not set.
+func (item *InvoiceDetailItem) Qty() decimal.Decimal {
+	if item.Quantity.IsZero() {
+		return decimal.NewFromInt(1)
 	}
+	return item.Quantity
+}
 
-	if err := yaml.Unmarshal(data, params); err != nil {
-		logrus.WithError(err).Fatalf("failed to unmarshal YAML")
-		return err
+// LineTotal returns quantity × unit price minus discount for itemized lines,
+// or TotalExcludeTax for lines that only carry totals.
+func (item *InvoiceDetailItem) LineTotal() decimal.Decimal {
+	if !item.IsItemized() {
+		return item.TotalExcludeTax
 	}
+	return item.Qty().Mul(item.UnitPrice).Sub(item.Discount)
+}
+
+// HasAmount reports whether the item carries an amount, rather than only
+// describing the work the summary bills.
+func (item *InvoiceDetailItem) HasAmount() bool {
+	return !item.LineTotal().IsZero() || !item.TotalIncludeTax.IsZero() || !item.TotalExcludeTax.IsZero()
+}
+
+// Reverse returns a copy of the item with all amounts negated, as used by
+// credit notes.
+func (item InvoiceDetailItem) Reverse() InvoiceDetailItem {
+	item.URLs = append([]string(nil), item.URLs...)
+	if item.IsItemized() {
+		item.Quantity = item.Qty().Neg()
+		item.Discount = item.Discount.Neg()
+	}
+	item.TotalExcludeTax = item.TotalExcludeTax.Neg()
+	item.TotalIncludeTax = item.TotalIncludeTax.Neg()
+	item.Tax = item.Tax.Neg()
+	return item
+}
+
+// Load decodes the params from a YAML or JSON file.
+func (params *InvoiceParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadBytes decodes the params from YAML or JSON data.
+func (params *InvoiceParams) LoadBytes(data []byte) error {
+	return decode("", data, params)
+}
 
-	return nil
+// LoadReader decodes the params from YAML or JSON read from r.
+func (params *InvoiceParams) LoadReader(r io.Reader) error {
+	return loadReader(r, params)
 }
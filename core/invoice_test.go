@@ -0,0 +1,470 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeSummary(t *testing.T) {
+	params := &InvoiceParams{
+		Summary: InvoiceSummary{
+			TaxRate:  decimal.RequireFromString("0.1"),
+			Rounding: RoundingPerLine,
+		},
+		DetailItems: []InvoiceDetailItem{
+			{Quantity: decimal.NewFromInt(3), UnitPrice: decimal.RequireFromString("33.35")},
+			{TotalExcludeTax: decimal.RequireFromString("10.05")},
+			{TotalIncludeTax: decimal.NewFromInt(110)},
+		},
+	}
+
+	totals := params.ComputeSummary(2)
+	if !totals.Subtotal.Equal(decimal.RequireFromString("210.10")) {
+		t.Fatalf("unexpected subtotal: %s", totals.Subtotal)
+	}
+	// 10.01 + 1.01 + 10.00 when rounding every line
+	if !totals.Tax.Equal(decimal.RequireFromString("21.02")) {
+		t.Fatalf("unexpected tax: %s", totals.Tax)
+	}
+
+	params.Summary.Rounding = RoundingPerDocument
+	totals = params.ComputeSummary(2)
+	if !totals.Tax.Equal(decimal.RequireFromString("21.01")) {
+		t.Fatalf("unexpected tax: %s", totals.Tax)
+	}
+	if !totals.Total.Equal(decimal.RequireFromString("231.11")) {
+		t.Fatalf("unexpected total: %s", totals.Total)
+	}
+}
+
+func TestCheckSummary(t *testing.T) {
+	params := &InvoiceParams{
+		Summary: InvoiceSummary{
+			TotalExcludeTax: decimal.NewFromInt(500),
+			TaxRate:         decimal.RequireFromString("0.1"),
+		},
+		DetailItems: []InvoiceDetailItem{
+			{TotalExcludeTax: decimal.NewFromInt(200)},
+			{TotalExcludeTax: decimal.NewFromInt(300)},
+		},
+	}
+	if err := params.CheckSummary(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params.Summary.TotalExcludeTax = decimal.NewFromInt(400)
+	var mismatch *SummaryMismatchError
+	if err := params.CheckSummary(0); !errors.As(err, &mismatch) || mismatch.Field != "total_exclude_tax" {
+		t.Fatalf("expected a total_exclude_tax mismatch, got %v", err)
+	}
+	report := params.Validate()
+	found := false
+	for _, err := range report.Errors {
+		found = found || err.Path == "summary.total_exclude_tax"
+	}
+	if !found {
+		t.Fatalf("expected Validate to report the mismatch without compute, got %v", report.Errors)
+	}
+
+	// items only describing the work cannot contradict the summary
+	params.DetailItems = []InvoiceDetailItem{{Title: "Development"}}
+	if err := params.CheckSummary(0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTaxBreakdown(t *testing.T) {
+	params := &InvoiceParams{
+		Summary: InvoiceSummary{
+			TaxRate: decimal.RequireFromString("0.1"),
+		},
+		DetailItems: []InvoiceDetailItem{
+			{TotalExcludeTax: decimal.NewFromInt(32800)},
+			{TotalExcludeTax: decimal.NewFromInt(24500), TaxRate: decimal.RequireFromString("0.08"), TaxCategory: TaxCategoryReduced},
+			{TotalExcludeTax: decimal.NewFromInt(1000)},
+			{TotalExcludeTax: decimal.NewFromInt(12000), TaxCategory: TaxCategoryZero},
+		},
+	}
+
+	groups := params.TaxBreakdown(0)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	if !groups[0].Subtotal.Equal(decimal.NewFromInt(33800)) || !groups[0].Tax.Equal(decimal.NewFromInt(3380)) {
+		t.Fatalf("unexpected standard rate group: %+v", groups[0])
+	}
+	if groups[1].Category != TaxCategoryReduced || !groups[1].Tax.Equal(decimal.NewFromInt(1960)) {
+		t.Fatalf("unexpected reduced rate group: %+v", groups[1])
+	}
+	if groups[2].Category != TaxCategoryZero || !groups[2].Tax.IsZero() {
+		t.Fatalf("unexpected zero rate group: %+v", groups[2])
+	}
+	if !params.HasMixedTaxRates() {
+		t.Fatal("expected mixed tax rates")
+	}
+}
+
+func TestValidateQualifiedInvoice(t *testing.T) {
+	params := &InvoiceParams{
+		CompanyName:   "ABC Inc",
+		TaxNumber:     "T1234567890123",
+		Date:          time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+		BillToCompany: "XYZ LLC",
+		Summary: InvoiceSummary{
+			TaxRate: decimal.RequireFromString("0.1"),
+		},
+		DetailItems: []InvoiceDetailItem{
+			{Date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), TotalExcludeTax: decimal.NewFromInt(1000)},
+		},
+	}
+	if err := params.ValidateQualifiedInvoice(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params.TaxNumber = "T123"
+	params.BillToCompany = ""
+	if err := params.ValidateQualifiedInvoice(); err == nil {
+		t.Fatal("expected an error for a bad registration number and missing recipient")
+	}
+}
+
+func TestQualifiedInvoiceSummaryOnly(t *testing.T) {
+	params := &InvoiceParams{}
+	if err := params.Load("../sample-params/invoice-2.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	params.QualifiedInvoice = true
+
+	// items without amounts leave the typed totals in place
+	totals := params.Totals(0)
+	if !totals.Subtotal.Equal(decimal.NewFromInt(500000)) || !totals.Tax.Equal(decimal.NewFromInt(50000)) || !totals.Total.Equal(decimal.NewFromInt(550000)) {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+
+	var fieldErrs FieldErrors
+	if err := params.ValidateQualifiedInvoice(); !errors.As(err, &fieldErrs) || len(fieldErrs) != 2 || fieldErrs[0].Path != "detail_items[0]" || fieldErrs[1].Path != "detail_items[1]" {
+		t.Fatalf("expected both items to miss an amount, got %v", err)
+	}
+}
+
+func TestMixedTaxRatesSummaryOnly(t *testing.T) {
+	params := &InvoiceParams{}
+	if err := params.Load("../sample-params/invoice-2.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	params.DetailItems[1].TaxRate = decimal.RequireFromString("0.08")
+	params.DetailItems[1].TaxCategory = TaxCategoryReduced
+
+	if totals := params.Totals(0); !totals.Total.Equal(decimal.NewFromInt(550000)) {
+		t.Fatalf("expected the typed totals, got %+v", totals)
+	}
+	found := false
+	for _, err := range params.Validate().Errors {
+		found = found || err.Path == "detail_items"
+	}
+	if !found {
+		t.Fatal("expected an error for mixed tax rates without amounts")
+	}
+}
+
+func TestQuoteToInvoiceParams(t *testing.T) {
+	quote := &QuoteParams{}
+	if err := quote.Load("../sample-params/quote-1.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	typed := *quote
+	typed.Summary.Compute = false
+	typed.Summary.TotalExcludeTax = decimal.NewFromInt(4500)
+	invoiceDate := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name  string
+		quote *QuoteParams
+		id    string
+		date  time.Time
+	}{
+		// the view the quote PDF is rendered from
+		{"quote", quote, quote.ID, quote.Date},
+		{"accepted", quote, "INV-20240401", invoiceDate},
+		{"typed summary", &typed, "INV-20240401", invoiceDate},
+	} {
+		params := tc.quote.ToInvoiceParams(tc.id, tc.date)
+		if params.ID != tc.id || !params.Date.Equal(tc.date) {
+			t.Errorf("%s: unexpected ID %s and date %s", tc.name, params.ID, params.Date)
+		}
+		if params.CompanyName != tc.quote.CompanyName || params.TaxNumber != tc.quote.TaxNumber || params.BillToCompany != tc.quote.BillToCompany || params.Currency != tc.quote.Currency {
+			t.Errorf("%s: parties not carried over: %+v", tc.name, params)
+		}
+		totals := params.Totals(CurrencyPlaces(params.Currency))
+		if !totals.Subtotal.Equal(decimal.NewFromInt(4500)) || !totals.Tax.Equal(decimal.NewFromInt(450)) || !totals.Total.Equal(decimal.NewFromInt(4950)) {
+			t.Errorf("%s: unexpected totals %+v", tc.name, totals)
+		}
+		if len(params.DetailItems) != len(tc.quote.DetailItems) {
+			t.Fatalf("%s: expected %d items, got %d", tc.name, len(tc.quote.DetailItems), len(params.DetailItems))
+		}
+		// the invoice must not share the items of the quote
+		params.DetailItems[0].Title = "changed"
+		if tc.quote.DetailItems[0].Title == "changed" {
+			t.Errorf("%s: items are shared with the quote", tc.name)
+		}
+	}
+
+	// the validity date is checked on the quote, not carried to invoices
+	quote.ValidUntil = quote.Date.AddDate(0, 0, -1)
+	found := false
+	for _, err := range quote.Validate().Errors {
+		found = found || err.Path == "valid_until"
+	}
+	if !found {
+		t.Error("expected an error for a validity date before the quote date")
+	}
+}
+
+func TestRequiresRevenueStamp(t *testing.T) {
+	for _, tc := range []struct {
+		currency    string
+		amount, tax int64
+		want        bool
+	}{
+		{"JPY", 49999, 0, false},
+		{"JPY", 50000, 0, true},
+		{"円", 50000, 0, true},
+		// the threshold excludes separately stated tax
+		{"JPY", 54999, 5000, false},
+		{"JPY", 55000, 5000, true},
+		// tax included without being stated counts in full
+		{"JPY", 54999, 0, true},
+		{"USD", 100000, 0, false},
+	} {
+		params := &ReceiptParams{Currency: tc.currency, Amount: decimal.NewFromInt(tc.amount), Tax: decimal.NewFromInt(tc.tax)}
+		if got := params.RequiresRevenueStamp(); got != tc.want {
+			t.Errorf("%s %d (tax %d): expected %v, got %v", tc.currency, tc.amount, tc.tax, tc.want, got)
+		}
+	}
+}
+
+func TestNewReceiptFromInvoice(t *testing.T) {
+	invoice := &InvoiceParams{
+		ID:            "INV-1",
+		Currency:      "JPY",
+		CompanyName:   "ABC株式会社",
+		BillToCompany: "XYZ株式会社",
+		Summary: InvoiceSummary{
+			Title:           "開発費",
+			TotalExcludeTax: decimal.NewFromInt(49999),
+			Tax:             decimal.NewFromInt(5000),
+			TaxRate:         decimal.RequireFromString("0.1"),
+		},
+		Payment: InvoicePayment{Method: "bank transfer"},
+	}
+	paid := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	receipt := NewReceiptFromInvoice(invoice, ReceiptPayment{Date: paid})
+	if receipt.ID != "INV-1" || receipt.InvoiceID != "INV-1" || receipt.ReceivedFrom != "XYZ株式会社" || receipt.PaymentMethod != "bank transfer" || !receipt.Date.Equal(paid) {
+		t.Fatalf("unexpected receipt %+v", receipt)
+	}
+	// 54,999 yen including 5,000 yen of tax is below the threshold
+	if !receipt.Amount.Equal(decimal.NewFromInt(54999)) || !receipt.Tax.Equal(decimal.NewFromInt(5000)) || receipt.RequiresRevenueStamp() {
+		t.Fatalf("unexpected amount %s, tax %s or stamp", receipt.Amount, receipt.Tax)
+	}
+
+	invoice.Summary.TotalExcludeTax = decimal.NewFromInt(50000)
+	if receipt := NewReceiptFromInvoice(invoice, ReceiptPayment{Date: paid}); !receipt.RequiresRevenueStamp() {
+		t.Fatal("expected a receipt of 50,000 yen excluding tax to need a stamp")
+	}
+
+	// partial payments do not state the tax
+	receipt = NewReceiptFromInvoice(invoice, ReceiptPayment{ID: "R-1", Date: paid, Method: "cash", Amount: decimal.NewFromInt(30000)})
+	if receipt.ID != "R-1" || receipt.PaymentMethod != "cash" || !receipt.Amount.Equal(decimal.NewFromInt(30000)) || !receipt.Tax.IsZero() {
+		t.Fatalf("unexpected partial receipt %+v", receipt)
+	}
+
+	invoice.Currency = "USD"
+	if receipt := NewReceiptFromInvoice(invoice, ReceiptPayment{Date: paid}); receipt.RequiresRevenueStamp() {
+		t.Fatal("expected receipts in other currencies never to need a stamp")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	params := &InvoiceParams{}
+	if err := params.Load("../sample-params/missing.yaml"); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("expected ErrFileNotFound, got %v", err)
+	}
+
+	var parseErr *ParseError
+	if err := params.LoadBytes([]byte("id: a\n  date: : b")); !errors.As(err, &parseErr) || parseErr.Line != 2 {
+		t.Fatalf("expected a parse error on line 2, got %v", err)
+	}
+
+	var fieldErrs FieldErrors
+	err := params.LoadBytes([]byte("id: a\ndetail_items:\n  - title: b\n  - title: c\n    quantity: many\n"))
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "detail_items[1].quantity" || fieldErrs[0].Line != 5 {
+		t.Fatalf("expected a field error at detail_items[1].quantity, got %v", err)
+	}
+
+	err = params.LoadBytes([]byte("id: a\nsummary:\n  title: [x]\n"))
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "summary.title" || fieldErrs[0].Line != 3 {
+		t.Fatalf("expected a field error at summary.title, got %v", err)
+	}
+
+	for _, data := range []string{"[]", "\n- a\n"} {
+		err = params.LoadBytes([]byte(data))
+		if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "." || fieldErrs[0].Line == 0 {
+			t.Fatalf("%q: expected a field error at the root, got %v", data, err)
+		}
+	}
+}
+
+func TestSplitDocuments(t *testing.T) {
+	stream := "# first\nid: a\n---\n# empty\n--- \nid: b\ndetail_items:\n  - title: c\n    quantity: many\n...\n"
+	docs, err := SplitDocuments([]byte(stream))
+	if err != nil || len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %d: %v", len(docs), err)
+	}
+	if string(docs[0]) != "# first\nid: a\n" {
+		t.Fatalf("unexpected first document %q", docs[0])
+	}
+
+	// documents keep the lines of the stream
+	var fieldErrs FieldErrors
+	err = (&InvoiceParams{}).LoadBytes(docs[1])
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "detail_items[0].quantity" || fieldErrs[0].Line != 9 {
+		t.Fatalf("expected a field error on line 9, got %v", err)
+	}
+
+	var parseErr *ParseError
+	if _, err := SplitDocuments([]byte("id: a\n---\nid: b\n  date: : c\n")); !errors.As(err, &parseErr) || parseErr.Line != 4 {
+		t.Fatalf("expected a parse error on line 4, got %v", err)
+	}
+}
+
+func TestLoadDates(t *testing.T) {
+	yamlParams := &InvoiceParams{}
+	if err := yamlParams.LoadBytes([]byte("id: a\ndate: 2024/02/10\nsummary:\n  period_start: 2024-01-01\ndetail_items:\n  - date: \"2024/01/31\"\n")); err != nil {
+		t.Fatal(err)
+	}
+	jsonParams := &InvoiceParams{}
+	if err := jsonParams.LoadBytes([]byte(`{"id": "a", "date": "2024-02-10", "summary": {"period_start": "2024/01/01"}, "detail_items": [{"date": "2024-01-31T00:00:00Z"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	for _, params := range []*InvoiceParams{yamlParams, jsonParams} {
+		if !params.Date.Equal(time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)) ||
+			!params.Summary.PeriodStart.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+			!params.DetailItems[0].Date.Equal(time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected dates %v %v %v", params.Date, params.Summary.PeriodStart, params.DetailItems[0].Date)
+		}
+	}
+
+	var fieldErrs FieldErrors
+	err := jsonParams.LoadBytes([]byte("{\n  \"date\": \"10/02/2024\"\n}"))
+	if !errors.As(err, &fieldErrs) || fieldErrs[0].Path != "date" || fieldErrs[0].Line != 2 {
+		t.Fatalf("expected a field error at date, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	params := &InvoiceParams{
+		Date:     time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+		Currency: "XYZ",
+		Summary: InvoiceSummary{
+			PeriodStart: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			PeriodEnd:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			TaxRate:     decimal.NewFromInt(10),
+		},
+		PaymentTerms: "net thirty",
+		Status:       "unpaid",
+		DetailItems: []InvoiceDetailItem{
+			{Title: "Lunch boxes", TotalExcludeTax: decimal.NewFromInt(1000), TaxCategory: TaxCategoryReduced},
+		},
+	}
+
+	report := params.Validate()
+	paths := map[string]bool{}
+	for _, err := range report.Errors {
+		paths[err.Path] = true
+	}
+	for _, path := range []string{"id", "currency", "company_name", "bill_to_company", "summary.period_end", "summary.tax_rate", "detail_items[0].tax_rate", "payment_terms", "status"} {
+		if !paths[path] {
+			t.Errorf("expected an error at %s, got %v", path, report.Errors)
+		}
+	}
+	if report.Err() == nil {
+		t.Error("expected Err to return the errors")
+	}
+
+	params.DetailItems = nil
+	if len(params.Validate().Warnings) == 0 {
+		t.Error("expected a warning for missing detail items")
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	for currency, places := range map[string]int32{"JPY": 0, "円": 0, "USD": 2, "EUR": 2} {
+		if !IsKnownCurrency(currency) || CurrencyPlaces(currency) != places {
+			t.Errorf("%s: expected a known currency with %d places", currency, places)
+		}
+	}
+	// currencies without minor units are only supported for the yen
+	for _, currency := range []string{"KRW", "VND", "XYZ"} {
+		if IsKnownCurrency(currency) {
+			t.Errorf("%s: expected an unknown currency", currency)
+		}
+	}
+}
+
+func TestPaymentTerms(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	issued := date(1, 31)
+	for terms, want := range map[string]time.Time{
+		"net-30":            date(3, 1),
+		"Net 0":             issued,
+		"due-on-receipt":    issued,
+		"eom":               issued,
+		"end-of-next-month": date(2, 29),
+		"月末締め翌月末払い":         date(2, 29),
+		"eom+2":             date(3, 31),
+		"day-10":            date(2, 10),
+		"day-31+1":          date(2, 29),
+	} {
+		parsed, err := ParsePaymentTerms(terms)
+		if err != nil {
+			t.Fatalf("%s: %v", terms, err)
+		}
+		if got := parsed.DueDate(issued); !got.Equal(want) {
+			t.Errorf("%s: expected %s, got %s", terms, want.Format("2006/01/02"), got.Format("2006/01/02"))
+		}
+	}
+	for _, terms := range []string{"net-", "eom-1", "day-0", "day-32", "soon"} {
+		if _, err := ParsePaymentTerms(terms); err == nil {
+			t.Errorf("%s: expected an error", terms)
+		}
+	}
+
+	// the 10th of February 2024 is a Saturday and the 12th a holiday
+	holidays := []time.Time{date(2, 12)}
+	params := &InvoiceParams{Date: issued, PaymentTerms: "day-10"}
+	for rule, want := range map[string]time.Time{
+		"":                   date(2, 10),
+		BusinessDayFollowing: date(2, 13),
+		BusinessDayPreceding: date(2, 9),
+	} {
+		params.BusinessDay = rule
+		if got := params.ComputeDueDate(holidays); !got.Equal(want) {
+			t.Errorf("%q: expected %s, got %s", rule, want.Format("2006/01/02"), got.Format("2006/01/02"))
+		}
+	}
+
+	params.DueDate = date(2, 20)
+	if got := params.ComputeDueDate(holidays); !got.Equal(params.DueDate) {
+		t.Errorf("expected the given due date, got %s", got.Format("2006/01/02"))
+	}
+	params.DueDate, params.PaymentTerms = time.Time{}, ""
+	if got := params.ComputeDueDate(holidays); !got.IsZero() {
+		t.Errorf("expected no due date without terms, got %s", got.Format("2006/01/02"))
+	}
+}
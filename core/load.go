@@ -0,0 +1,226 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// rootPath addresses errors about the document as a whole.
+const rootPath = "."
+
+// loadFile reads the params file and decodes it into out.
+func loadFile(filename string, out any) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+		}
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return decode(filename, data, out)
+}
+
+// loadReader reads params from r and decodes them into out.
+func loadReader(r io.Reader, out any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read params: %w", err)
+	}
+	return decode("", data, out)
+}
+
+// decode decodes YAML or JSON params into out. Malformed documents are reported as a
+// *ParseError and fields that cannot be decoded as FieldErrors.
+func decode(filename string, data []byte, out any) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return newParseError(filename, err)
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	if errs := normalizeDates(root.Content[0], reflect.TypeOf(out), "", ""); len(errs) > 0 {
+		return errs
+	}
+
+	err := root.Decode(out)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		errs := FieldErrors{}
+		for _, msg := range typeErr.Errors {
+			ferr := &FieldError{Message: msg}
+			if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				ferr.Message = m[2]
+				ferr.Path, ferr.Line, ferr.Column = pathAtLine(root.Content[0], "", line)
+			}
+			// params are mappings, any other document has the wrong type
+			if ferr.Path == "" || root.Content[0].Kind != yaml.MappingNode {
+				ferr.Path, ferr.Line, ferr.Column = rootPath, root.Content[0].Line, root.Content[0].Column
+			}
+			errs = append(errs, ferr)
+		}
+		return errs
+	}
+
+	// decoders such as decimal.Decimal.UnmarshalText abort without any
+	// position, so narrow the document down to the failing node
+	path, node := locateDecodeError(root.Content[0], reflect.TypeOf(out).Elem(), func(n *yaml.Node) *yaml.Node { return n }, "")
+	if path == "" {
+		path = rootPath
+	}
+	return FieldErrors{{Path: path, Line: node.Line, Column: node.Column, Message: err.Error()}}
+}
+
+func newParseError(filename string, err error) *ParseError {
+	perr := &ParseError{Filename: filename, Message: err.Error()}
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		perr.Line, _ = strconv.Atoi(m[1])
+		perr.Message = m[2]
+	}
+	return perr
+}
+
+// SplitDocuments splits a multi-document YAML stream into the data of its
+// documents, skipping empty ones. Documents are cut from the original data
+// at their "---" markers and padded with as many empty lines as precede
+// them, so that errors decoding them report lines of the stream.
+func SplitDocuments(data []byte) ([][]byte, error) {
+	type marker struct{ line, offset int }
+	markers := []marker{}
+	for line, offset := 1, 0; offset < len(data); line++ {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			end = len(data) - offset
+		}
+		if isDocumentMarker(data[offset : offset+end]) {
+			markers = append(markers, marker{line, offset})
+		}
+		offset += end + 1
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	nodes := []*yaml.Node{}
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, newParseError("", err)
+		}
+		// documents between consecutive markers hold a null scalar
+		if len(node.Content) > 0 && node.Content[0].Tag != "!!null" {
+			nodes = append(nodes, &node)
+		}
+	}
+
+	docs := [][]byte{}
+	for _, node := range nodes {
+		// the document runs from the last marker up to its first node to
+		// the next marker
+		first := node.Content[0].Line
+		startLine, start, end := 1, 0, len(data)
+		for _, m := range markers {
+			if m.line > first {
+				end = m.offset
+				break
+			}
+			startLine, start = m.line, m.offset
+		}
+		doc := append(bytes.Repeat([]byte("\n"), startLine-1), data[start:end]...)
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// isDocumentMarker reports whether the line starts a YAML document.
+func isDocumentMarker(line []byte) bool {
+	line = bytes.TrimRight(line, "\r")
+	return bytes.HasPrefix(line, []byte("---")) && (len(line) == 3 || line[3] == ' ' || line[3] == '\t')
+}
+
+// pathAtLine returns the YAML path and position of the first value whose
+// key is on the given line.
+func pathAtLine(n *yaml.Node, path string, line int) (string, int, int) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for ix := 0; ix+1 < len(n.Content); ix += 2 {
+			key, value := n.Content[ix], n.Content[ix+1]
+			if key.Line == line {
+				return joinPath(path, key.Value), value.Line, value.Column
+			}
+			if p, l, c := pathAtLine(value, joinPath(path, key.Value), line); l > 0 {
+				return p, l, c
+			}
+		}
+	case yaml.SequenceNode:
+		for ix, item := range n.Content {
+			if p, l, c := pathAtLine(item, fmt.Sprintf("%s[%d]", path, ix), line); l > 0 {
+				return p, l, c
+			}
+		}
+	default:
+		if n.Line == line {
+			return path, n.Line, n.Column
+		}
+	}
+	return path, 0, 0
+}
+
+// locateDecodeError returns the deepest node that still fails to decode when
+// it is the only content of the document. wrap rebuilds the document around
+// a node at the current position.
+func locateDecodeError(n *yaml.Node, typ reflect.Type, wrap func(*yaml.Node) *yaml.Node, path string) (string, *yaml.Node) {
+	fails := func(candidate *yaml.Node) bool {
+		return wrap(candidate).Decode(reflect.New(typ).Interface()) != nil
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		for ix := 0; ix+1 < len(n.Content); ix += 2 {
+			key, value := n.Content[ix], n.Content[ix+1]
+			single := func(v *yaml.Node) *yaml.Node {
+				return &yaml.Node{Kind: yaml.MappingNode, Tag: n.Tag, Content: []*yaml.Node{key, v}}
+			}
+			if fails(single(value)) {
+				return locateDecodeError(value, typ, func(v *yaml.Node) *yaml.Node { return wrap(single(v)) }, joinPath(path, key.Value))
+			}
+		}
+	case yaml.SequenceNode:
+		for ix, item := range n.Content {
+			single := func(v *yaml.Node) *yaml.Node {
+				return &yaml.Node{Kind: yaml.SequenceNode, Tag: n.Tag, Content: []*yaml.Node{v}}
+			}
+			if fails(single(item)) {
+				return locateDecodeError(item, typ, func(v *yaml.Node) *yaml.Node { return wrap(single(v)) }, fmt.Sprintf("%s[%d]", path, ix))
+			}
+		}
+	}
+	return path, n
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return strings.Join([]string{path, key}, ".")
+}
@@ -1,64 +1,67 @@
 package core
 
 import (
-	"os"
+	"io"
 	"time"
 
 	"github.com/shopspring/decimal"
-	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 )
 
 type (
 	PaymentStatementPayer struct {
-		Name      string `yaml:"name"`
-		Address   string `yaml:"addr"`
-		TaxNumber string `yaml:"tax_number"`
-		Contact   string `yaml:"contact"`
+		Name      string `yaml:"name" json:"name"`
+		Address   string `yaml:"addr" json:"addr"`
+		TaxNumber string `yaml:"tax_number" json:"tax_number"`
+		Contact   string `yaml:"contact" json:"contact"`
 	}
 
 	PaymentStatementPayee struct {
-		Name      string `yaml:"name"`
-		Address   string `yaml:"addr"`
-		TaxNumber string `yaml:"tax_number"`
-		Contact   string `yaml:"contact"`
+		Name      string `yaml:"name" json:"name"`
+		Address   string `yaml:"addr" json:"addr"`
+		TaxNumber string `yaml:"tax_number" json:"tax_number"`
+		Contact   string `yaml:"contact" json:"contact"`
 	}
 
 	PaymentStatementDetailItem struct {
-		Title              string          `yaml:"title"`
-		Desc               string          `yaml:"desc"`
-		Amount             decimal.Decimal `yaml:"amount"`
-		WithholdingTaxRate decimal.Decimal `yaml:"withholding_tax_rate"`
+		Title              string          `yaml:"title" json:"title"`
+		Desc               string          `yaml:"desc" json:"desc"`
+		Amount             decimal.Decimal `yaml:"amount" json:"amount"`
+		WithholdingTaxRate decimal.Decimal `yaml:"withholding_tax_rate" json:"withholding_tax_rate"`
 	}
 
 	PaymentStatementParams struct {
-		ID          string    `yaml:"id"`
-		Date        time.Time `yaml:"date" time_format:"2006/01/02"`
-		Currency    string    `yaml:"currency"`
-		CompanySeal string    `yaml:"company_seal"`
-		PeriodStart time.Time `yaml:"period_start" time_format:"2006/01/02"`
-		PeriodEnd   time.Time `yaml:"period_end" time_format:"2006/01/02"`
+		ID          string    `yaml:"id" json:"id"`
+		Date        time.Time `yaml:"date" json:"date" time_format:"2006/01/02"`
+		Currency    string    `yaml:"currency" json:"currency"`
+		CompanySeal string    `yaml:"company_seal" json:"company_seal"`
+		CompanyLogo string    `yaml:"company_logo" json:"company_logo"`
+		PeriodStart time.Time `yaml:"period_start" json:"period_start" time_format:"2006/01/02"`
+		PeriodEnd   time.Time `yaml:"period_end" json:"period_end" time_format:"2006/01/02"`
 
-		PaymentChannel string `yaml:"payment_channel"`
-		PaymentTxID    string `yaml:"payment_tx_id"`
+		PaymentChannel string `yaml:"payment_channel" json:"payment_channel"`
+		PaymentTxID    string `yaml:"payment_tx_id" json:"payment_tx_id"`
 
-		Payer       PaymentStatementPayer        `yaml:"payer"`
-		Payee       PaymentStatementPayee        `yaml:"payee"`
-		DetailItems []PaymentStatementDetailItem `yaml:"detail_items"`
+		Payer       PaymentStatementPayer        `yaml:"payer" json:"payer"`
+		Payee       PaymentStatementPayee        `yaml:"payee" json:"payee"`
+		DetailItems []PaymentStatementDetailItem `yaml:"detail_items" json:"detail_items"`
+
+		// Notes and terms as markdown-lite text, like those of invoices.
+		Notes string `yaml:"notes" json:"notes"`
+		Terms string `yaml:"terms" json:"terms"`
 	}
 )
 
+// Load decodes the params from a YAML or JSON file.
 func (params *PaymentStatementParams) Load(filename string) error {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		logrus.WithError(err).Fatalf("failed to read YAML file")
-		return err
-	}
+	return loadFile(filename, params)
+}
 
-	if err := yaml.Unmarshal(data, params); err != nil {
-		logrus.WithError(err).Fatalf("failed to unmarshal YAML")
-		return err
-	}
+// LoadBytes decodes the params from YAML or JSON data.
+func (params *PaymentStatementParams) LoadBytes(data []byte) error {
+	return decode("", data, params)
+}
 
-	return nil
+// LoadReader decodes the params from YAML or JSON read from r.
+func (params *PaymentStatementParams) LoadReader(r io.Reader) error {
+	return loadReader(r, params)
 }
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var registrationNumberPattern = regexp.MustCompile(`^T\d{13}$`)
+
+// IsRegistrationNumber reports whether number is a Japanese qualified invoice
+// issuer registration number, T followed by 13 digits.
+func IsRegistrationNumber(number string) bool {
+	return registrationNumberPattern.MatchString(number)
+}
+
+// ValidateQualifiedInvoice checks that the params carry every element a
+// Japanese qualified invoice (適格請求書) requires and returns the missing
+// elements as FieldErrors.
+func (params *InvoiceParams) ValidateQualifiedInvoice() error {
+	if errs := params.qualifiedInvoiceErrors(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (params *InvoiceParams) qualifiedInvoiceErrors() FieldErrors {
+	errs := FieldErrors{}
+	if params.CompanyName == "" {
+		errs = append(errs, &FieldError{Path: "company_name", Message: "issuer name is required"})
+	}
+	if !IsRegistrationNumber(params.TaxNumber) {
+		errs = append(errs, &FieldError{Path: "tax_number", Message: fmt.Sprintf("%q is not a registration number of the form T followed by 13 digits", params.TaxNumber)})
+	}
+	if params.Date.IsZero() {
+		errs = append(errs, &FieldError{Path: "date", Message: "issue date is required"})
+	}
+	if params.BillToCompany == "" {
+		errs = append(errs, &FieldError{Path: "bill_to_company", Message: "recipient name is required"})
+	}
+	if len(params.DetailItems) == 0 {
+		errs = append(errs, &FieldError{Path: "detail_items", Message: "at least one item is required"})
+	}
+	hasPeriod := !params.Summary.PeriodStart.IsZero() && !params.Summary.PeriodEnd.IsZero()
+	for ix, item := range params.DetailItems {
+		if item.Date.IsZero() && !hasPeriod {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("detail_items[%d].date", ix), Message: "transaction date is required"})
+		}
+		// the totals per tax rate are summed from the items
+		if !item.HasAmount() {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("detail_items[%d]", ix), Message: "amount is required"})
+		}
+		if item.TaxCategory != TaxCategoryZero && item.TaxCategory != TaxCategoryExempt && params.ItemTaxRate(&item).IsZero() {
+			errs = append(errs, &FieldError{Path: fmt.Sprintf("detail_items[%d].tax_rate", ix), Message: "tax rate is required"})
+		}
+	}
+	return errs
+}
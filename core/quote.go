@@ -0,0 +1,74 @@
+package core
+
+import (
+	"io"
+	"time"
+)
+
+type (
+	QuoteParams struct {
+		ID           string    `yaml:"id" json:"id"`
+		TaxNumber    string    `yaml:"tax_number" json:"tax_number"`
+		Date         time.Time `yaml:"date" json:"date" time_format:"2006/01/02"`
+		ValidUntil   time.Time `yaml:"valid_until" json:"valid_until" time_format:"2006/01/02"`
+		Currency     string    `yaml:"currency" json:"currency"`
+		CompanyName  string    `yaml:"company_name" json:"company_name"`
+		CompanyAddr  string    `yaml:"company_address" json:"company_address"`
+		CompanyEmail string    `yaml:"company_email" json:"company_email"`
+		CompanySeal  string    `yaml:"company_seal" json:"company_seal"`
+		CompanyLogo  string    `yaml:"company_logo" json:"company_logo"`
+
+		BillToCompany string `yaml:"bill_to_company" json:"bill_to_company"`
+		BillToAddress string `yaml:"bill_to_address" json:"bill_to_address"`
+
+		// Summary
+		Summary InvoiceSummary `yaml:"summary" json:"summary"`
+
+		// Details
+		DetailItems []InvoiceDetailItem `yaml:"detail_items" json:"detail_items"`
+
+		// Terms and conditions of the quote
+		Terms string `yaml:"terms" json:"terms"`
+	}
+)
+
+// Load decodes the params from a YAML or JSON file.
+func (params *QuoteParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadBytes decodes the params from YAML or JSON data.
+func (params *QuoteParams) LoadBytes(data []byte) error {
+	return decode("", data, params)
+}
+
+// LoadReader decodes the params from YAML or JSON read from r.
+func (params *QuoteParams) LoadReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// ToInvoiceParams converts an accepted quote into the params of an invoice
+// with the given ID and issue date. Payment instructions are left empty for
+// the caller to fill in.
+func (params *QuoteParams) ToInvoiceParams(id string, date time.Time) *InvoiceParams {
+	items := make([]InvoiceDetailItem, len(params.DetailItems))
+	copy(items, params.DetailItems)
+	for ix := range items {
+		items[ix].URLs = append([]string(nil), params.DetailItems[ix].URLs...)
+	}
+	return &InvoiceParams{
+		ID:            id,
+		TaxNumber:     params.TaxNumber,
+		Date:          date,
+		Currency:      params.Currency,
+		CompanyName:   params.CompanyName,
+		CompanyAddr:   params.CompanyAddr,
+		CompanyEmail:  params.CompanyEmail,
+		CompanySeal:   params.CompanySeal,
+		CompanyLogo:   params.CompanyLogo,
+		BillToCompany: params.BillToCompany,
+		BillToAddress: params.BillToAddress,
+		Summary:       params.Summary,
+		DetailItems:   items,
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RevenueStampThreshold is the amount (excluding separately stated tax) from
+// which a Japanese paper receipt needs a revenue stamp (収入印紙).
+var RevenueStampThreshold = decimal.NewFromInt(50000)
+
+type (
+	ReceiptParams struct {
+		ID           string    `yaml:"id" json:"id"`
+		TaxNumber    string    `yaml:"tax_number" json:"tax_number"`
+		Date         time.Time `yaml:"date" json:"date" time_format:"2006/01/02"`
+		Currency     string    `yaml:"currency" json:"currency"`
+		CompanyName  string    `yaml:"company_name" json:"company_name"`
+		CompanyAddr  string    `yaml:"company_address" json:"company_address"`
+		CompanyEmail string    `yaml:"company_email" json:"company_email"`
+		CompanySeal  string    `yaml:"company_seal" json:"company_seal"`
+		CompanyLogo  string    `yaml:"company_logo" json:"company_logo"`
+
+		ReceivedFrom string `yaml:"received_from" json:"received_from"`
+
+		// Amount received, including tax
+		Amount  decimal.Decimal `yaml:"amount" json:"amount"`
+		Tax     decimal.Decimal `yaml:"tax" json:"tax"`
+		TaxRate decimal.Decimal `yaml:"tax_rate" json:"tax_rate"`
+
+		For           string `yaml:"for" json:"for"`
+		PaymentMethod string `yaml:"payment_method" json:"payment_method"`
+		InvoiceID     string `yaml:"invoice_id" json:"invoice_id"`
+	}
+
+	// ReceiptPayment describes the payment a receipt is issued for.
+	ReceiptPayment struct {
+		ID     string
+		Date   time.Time
+		Method string
+		// Amount defaults to the invoice total when zero
+		Amount decimal.Decimal
+	}
+)
+
+// Load decodes the params from a YAML or JSON file.
+func (params *ReceiptParams) Load(filename string) error {
+	return loadFile(filename, params)
+}
+
+// LoadBytes decodes the params from YAML or JSON data.
+func (params *ReceiptParams) LoadBytes(data []byte) error {
+	return decode("", data, params)
+}
+
+// LoadReader decodes the params from YAML or JSON read from r.
+func (params *ReceiptParams) LoadReader(r io.Reader) error {
+	return loadReader(r, params)
+}
+
+// NewReceiptFromInvoice creates the params of a receipt for a payment made
+// against the given invoice.
+func NewReceiptFromInvoice(invoice *InvoiceParams, payment ReceiptPayment) *ReceiptParams {
+	totals := invoice.Totals(CurrencyPlaces(invoice.Currency))
+	amount, tax := payment.Amount, decimal.Zero
+	if amount.IsZero() || amount.Equal(totals.Total) {
+		amount, tax = totals.Total, totals.Tax
+	}
+	method := payment.Method
+	if method == "" {
+		method = invoice.Payment.Method
+	}
+	id := payment.ID
+	if id == "" {
+		id = invoice.ID
+	}
+	return &ReceiptParams{
+		ID:            id,
+		TaxNumber:     invoice.TaxNumber,
+		Date:          payment.Date,
+		Currency:      invoice.Currency,
+		CompanyName:   invoice.CompanyName,
+		CompanyAddr:   invoice.CompanyAddr,
+		CompanyEmail:  invoice.CompanyEmail,
+		CompanySeal:   invoice.CompanySeal,
+		CompanyLogo:   invoice.CompanyLogo,
+		ReceivedFrom:  invoice.BillToCompany,
+		Amount:        amount,
+		Tax:           tax,
+		TaxRate:       invoice.Summary.TaxRate,
+		For:           invoice.Summary.Title,
+		PaymentMethod: method,
+		InvoiceID:     invoice.ID,
+	}
+}
+
+// RequiresRevenueStamp reports whether the receipt needs a revenue stamp
+// under Japanese stamp duty rules.
+func (params *ReceiptParams) RequiresRevenueStamp() bool {
+	if !IsJPY(params.Currency) {
+		return false
+	}
+	return params.Amount.Sub(params.Tax).GreaterThanOrEqual(RevenueStampThreshold)
+}
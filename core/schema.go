@@ -0,0 +1,102 @@
+package core
+
+import (
+	"reflect"
+
+	"github.com/shopspring/decimal"
+)
+
+// SchemaDraft is the JSON Schema dialect of the schemas JSONSchema returns.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+// Schema is a JSON Schema document, restricted to the keywords needed to
+// describe params.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// schemaEnums lists the accepted values of string fields, keyed by their
+// JSON name.
+var schemaEnums = map[string][]string{
+	"tax_category": {"", TaxCategoryStandard, TaxCategoryReduced, TaxCategoryZero, TaxCategoryExempt},
+	"rounding":     {"", RoundingPerLine, RoundingPerDocument},
+}
+
+// JSONSchema describes the JSON form of params, such as *InvoiceParams, as
+// accepted by the loaders. Dates are strings in the field's time_format,
+// ISO 8601 or RFC 3339 form, and amounts are numbers or decimal strings.
+func JSONSchema(params any) *Schema {
+	typ := reflect.TypeOf(params)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	s := schemaOf(typ, "")
+	s.Schema = SchemaDraft
+	s.Title = typ.Name()
+	return s
+}
+
+func schemaOf(typ reflect.Type, layout string) *Schema {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		if layout == "" {
+			layout = DateLayout
+		}
+		return &Schema{
+			Type:        "string",
+			Description: "date as " + layout + ", 2006-01-02 or RFC 3339",
+			Pattern:     `^\d{4}[-/]\d{2}[-/]\d{2}([T ].*)?$`,
+		}
+	case typ == decimalType:
+		return &Schema{Type: []string{"number", "string"}, Pattern: `^-?\d+(\.\d+)?$`}
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(typ.Elem(), layout)}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		closed := false
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: &closed}
+		for ix := 0; ix < typ.NumField(); ix++ {
+			field := typ.Field(ix)
+			if !field.IsExported() {
+				continue
+			}
+			name := tagName(field.Tag.Get("json"), field.Name)
+			if name == "-" {
+				continue
+			}
+			prop := schemaOf(field.Type, field.Tag.Get("time_format"))
+			if enum, ok := schemaEnums[name]; ok && prop.Type == "string" {
+				prop.Enum = enum
+			}
+			s.Properties[name] = prop
+		}
+		return s
+	}
+	return &Schema{}
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	TaxCategoryStandard = "standard"
+	TaxCategoryReduced  = "reduced"
+	TaxCategoryZero     = "zero"
+	TaxCategoryExempt   = "exempt"
+)
+
+const (
+	// RoundingPerLine rounds the tax of every detail item before summing.
+	RoundingPerLine = "line"
+	// RoundingPerDocument sums the unrounded taxes and rounds once per tax rate.
+	RoundingPerDocument = "document"
+)
+
+type (
+	InvoiceTotals struct {
+		Subtotal decimal.Decimal
+		Tax      decimal.Decimal
+		Total    decimal.Decimal
+	}
+
+	// TaxBreakdown holds the subtotal and tax of all detail items sharing
+	// the same tax category and rate.
+	TaxBreakdown struct {
+		Category string
+		Rate     decimal.Decimal
+		Subtotal decimal.Decimal
+		Tax      decimal.Decimal
+	}
+
+	SummaryMismatchError struct {
+		Field    string
+		Provided decimal.Decimal
+		Computed decimal.Decimal
+	}
+)
+
+func (e *SummaryMismatchError) Error() string {
+	return fmt.Sprintf("summary %s %s does not match %s computed from detail items", e.Field, e.Provided, e.Computed)
+}
+
+// ItemTaxRate returns the tax rate applied to the item, falling back to the
+// summary tax rate when the item does not set its own. Zero-rated and exempt
+// items are never taxed. Reduced items must set their own rate, which
+// Validate checks.
+func (params *InvoiceParams) ItemTaxRate(item *InvoiceDetailItem) decimal.Decimal {
+	if item.TaxCategory == TaxCategoryZero || item.TaxCategory == TaxCategoryExempt {
+		return decimal.Zero
+	}
+	if !item.TaxRate.IsZero() {
+		return item.TaxRate
+	}
+	return params.Summary.TaxRate
+}
+
+// ItemAmounts returns the unrounded amount excluding tax and the tax of the item.
+func (params *InvoiceParams) ItemAmounts(item *InvoiceDetailItem) (decimal.Decimal, decimal.Decimal) {
+	rate := params.ItemTaxRate(item)
+	subtotal := item.LineTotal()
+	if subtotal.IsZero() && !item.TotalIncludeTax.IsZero() {
+		subtotal = item.TotalIncludeTax.Div(decimal.NewFromInt(1).Add(rate))
+		if !item.Tax.IsZero() {
+			subtotal = item.TotalIncludeTax.Sub(item.Tax)
+		}
+		return subtotal, item.TotalIncludeTax.Sub(subtotal)
+	}
+	if !item.Tax.IsZero() {
+		return subtotal, item.Tax
+	}
+	return subtotal, subtotal.Mul(rate)
+}
+
+// TaxBreakdown groups the detail items by tax category and rate, ordered by
+// descending rate, rounding amounts to the given number of decimal places.
+func (params *InvoiceParams) TaxBreakdown(places int32) []TaxBreakdown {
+	var groups []TaxBreakdown
+	for ix := range params.DetailItems {
+		item := &params.DetailItems[ix]
+		rate := params.ItemTaxRate(item)
+		category := item.TaxCategory
+		if category == "" {
+			category = TaxCategoryStandard
+		}
+		itemSubtotal, itemTax := params.ItemAmounts(item)
+		// qualified invoices must round the tax once per rate
+		if params.Summary.Rounding == RoundingPerLine && !params.QualifiedInvoice {
+			itemSubtotal = itemSubtotal.Round(places)
+			itemTax = itemTax.Round(places)
+		}
+
+		found := false
+		for gx := range groups {
+			if groups[gx].Category == category && groups[gx].Rate.Equal(rate) {
+				groups[gx].Subtotal = groups[gx].Subtotal.Add(itemSubtotal)
+				groups[gx].Tax = groups[gx].Tax.Add(itemTax)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, TaxBreakdown{
+				Category: category,
+				Rate:     rate,
+				Subtotal: itemSubtotal,
+				Tax:      itemTax,
+			})
+		}
+	}
+
+	for gx := range groups {
+		groups[gx].Subtotal = groups[gx].Subtotal.Round(places)
+		groups[gx].Tax = groups[gx].Tax.Round(places)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Rate.GreaterThan(groups[j].Rate)
+	})
+	return groups
+}
+
+// HasMixedTaxRates reports whether the detail items fall into more than one
+// tax category or rate.
+func (params *InvoiceParams) HasMixedTaxRates() bool {
+	return len(params.TaxBreakdown(2)) > 1
+}
+
+// ComputeSummary derives the invoice totals from the detail items, rounding
+// amounts to the given number of decimal places.
+func (params *InvoiceParams) ComputeSummary(places int32) InvoiceTotals {
+	var subtotal, tax decimal.Decimal
+	for _, group := range params.TaxBreakdown(places) {
+		subtotal = subtotal.Add(group.Subtotal)
+		tax = tax.Add(group.Tax)
+	}
+	return InvoiceTotals{
+		Subtotal: subtotal,
+		Tax:      tax,
+		Total:    subtotal.Add(tax),
+	}
+}
+
+// Totals returns the invoice totals, either as typed in the summary or
+// computed from the detail items when Summary.Compute is set or the items
+// carry more than one tax rate. Qualified invoices are always computed so
+// that the tax is rounded once per rate. Detail items without any amount
+// leave nothing to compute from, so the typed summary is used.
+func (params *InvoiceParams) Totals(places int32) InvoiceTotals {
+	if !params.HasItemAmounts() {
+		return params.Summary.Totals()
+	}
+	if params.Summary.Compute || params.QualifiedInvoice || params.HasMixedTaxRates() {
+		return params.ComputeSummary(places)
+	}
+	return params.Summary.Totals()
+}
+
+// Totals returns the totals as typed in the summary, filling in the tax and
+// the missing side of the total from the tax rate.
+func (summary *InvoiceSummary) Totals() InvoiceTotals {
+	var total, tax, subtotal decimal.Decimal
+	if !summary.TotalExcludeTax.IsZero() {
+		// tax excluded?
+		subtotal = summary.TotalExcludeTax
+		if !summary.Tax.IsZero() {
+			tax = summary.Tax.Round(2)
+		} else if summary.TaxRate.IsPositive() {
+			tax = subtotal.Mul(summary.TaxRate).Round(2)
+		}
+		total = subtotal.Add(tax).Round(2)
+	} else {
+		// tax included?
+		total = summary.TotalIncludeTax
+		subtotal = total.Div(decimal.NewFromFloat(1).Add(summary.TaxRate)).Round(2)
+		tax = total.Sub(subtotal).Round(2)
+	}
+	return InvoiceTotals{
+		Subtotal: subtotal,
+		Tax:      tax,
+		Total:    total,
+	}
+}
+
+// HasItemAmounts reports whether any detail item carries an amount.
+func (params *InvoiceParams) HasItemAmounts() bool {
+	for ix := range params.DetailItems {
+		if params.DetailItems[ix].HasAmount() {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSummary compares the typed summary with the one computed from the
+// detail items and returns a *SummaryMismatchError when they disagree. A
+// summary without any typed totals, or detail items without any amount, are
+// always consistent.
+func (params *InvoiceParams) CheckSummary(places int32) error {
+	if !params.HasItemAmounts() {
+		return nil
+	}
+	summary := params.Summary
+	computed := params.ComputeSummary(places)
+	if !summary.TotalExcludeTax.IsZero() && !summary.TotalExcludeTax.Round(places).Equal(computed.Subtotal) {
+		return &SummaryMismatchError{Field: "total_exclude_tax", Provided: summary.TotalExcludeTax, Computed: computed.Subtotal}
+	}
+	if !summary.Tax.IsZero() && !summary.Tax.Round(places).Equal(computed.Tax) {
+		return &SummaryMismatchError{Field: "tax", Provided: summary.Tax, Computed: computed.Tax}
+	}
+	if !summary.TotalIncludeTax.IsZero() && !summary.TotalIncludeTax.Round(places).Equal(computed.Total) {
+		return &SummaryMismatchError{Field: "total_include_tax", Provided: summary.TotalIncludeTax, Computed: computed.Total}
+	}
+	return nil
+}
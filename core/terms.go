@@ -0,0 +1,150 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// BusinessDayFollowing moves due dates on weekends and holidays to the
+	// next business day.
+	BusinessDayFollowing = "following"
+	// BusinessDayPreceding moves due dates on weekends and holidays to the
+	// previous business day, as Japanese invoices usually do.
+	BusinessDayPreceding = "preceding"
+)
+
+const (
+	InvoiceStatusPaid    = "paid"
+	InvoiceStatusOverdue = "overdue"
+)
+
+type (
+	// PaymentTerms are the terms of the payment_terms param: net terms due a
+	// number of days after the issue date, or terms due on a day of a month
+	// following the issue month.
+	PaymentTerms struct {
+		// Net terms are due Days after the issue date.
+		Net  bool
+		Days int
+		// Other terms are due on Day of the month Months after the issue
+		// month, 0 being the end of the month.
+		Months int
+		Day    int
+	}
+)
+
+// paymentTermsAliases are spelled out payment terms and their short form.
+var paymentTermsAliases = map[string]string{
+	"due-on-receipt":    "net-0",
+	"end-of-month":      "eom",
+	"end-of-next-month": "eom+1",
+	"月末締め当月末払い":         "eom",
+	"月末締め翌月末払い":         "eom+1",
+	"月末締め翌々月末払い":        "eom+2",
+}
+
+var (
+	netTermsPattern = regexp.MustCompile(`^net-?(\d+)$`)
+	eomTermsPattern = regexp.MustCompile(`^eom(?:\+(\d+))?$`)
+	dayTermsPattern = regexp.MustCompile(`^day-(\d+)(?:\+(\d+))?$`)
+)
+
+// ParsePaymentTerms parses payment terms of the forms
+//
+//	net-30             30 days after the issue date
+//	eom, eom+1         the end of the issue month, of the next month
+//	day-10, day-10+2   the 10th of the next month, of the month after
+//
+// and the aliases due-on-receipt, end-of-month, end-of-next-month,
+// 月末締め翌月末払い and 月末締め翌々月末払い.
+func ParsePaymentTerms(s string) (PaymentTerms, error) {
+	key := strings.Join(strings.Fields(strings.ToLower(s)), "-")
+	if alias, ok := paymentTermsAliases[key]; ok {
+		key = alias
+	}
+	atoi := func(s string, def int) int {
+		if s == "" {
+			return def
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	if m := netTermsPattern.FindStringSubmatch(key); m != nil {
+		return PaymentTerms{Net: true, Days: atoi(m[1], 0)}, nil
+	}
+	if m := eomTermsPattern.FindStringSubmatch(key); m != nil {
+		return PaymentTerms{Months: atoi(m[1], 0)}, nil
+	}
+	if m := dayTermsPattern.FindStringSubmatch(key); m != nil {
+		terms := PaymentTerms{Day: atoi(m[1], 0), Months: atoi(m[2], 1)}
+		if terms.Day < 1 || terms.Day > 31 {
+			return PaymentTerms{}, fmt.Errorf("day %d of payment terms %q is not a day of the month", terms.Day, s)
+		}
+		return terms, nil
+	}
+	return PaymentTerms{}, fmt.Errorf("unknown payment terms %q, expected net-30, eom+1 or day-10+1", s)
+}
+
+// DueDate returns the date an invoice issued on the given date is due.
+func (terms PaymentTerms) DueDate(issued time.Time) time.Time {
+	if terms.Net {
+		return issued.AddDate(0, 0, terms.Days)
+	}
+	first := time.Date(issued.Year(), issued.Month()+time.Month(terms.Months), 1, 0, 0, 0, 0, issued.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	if terms.Day == 0 || terms.Day > last {
+		return first.AddDate(0, 0, last-1)
+	}
+	return first.AddDate(0, 0, terms.Day-1)
+}
+
+// IsBusinessDay reports whether the date is neither on a weekend nor one of
+// the holidays.
+func IsBusinessDay(date time.Time, holidays []time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	y, m, d := date.Date()
+	for _, holiday := range holidays {
+		if hy, hm, hd := holiday.Date(); hy == y && hm == m && hd == d {
+			return false
+		}
+	}
+	return true
+}
+
+// AdjustBusinessDay moves the date to the following or preceding business
+// day by the rule, BusinessDayFollowing or BusinessDayPreceding. Other
+// rules leave the date as it is.
+func AdjustBusinessDay(date time.Time, rule string, holidays []time.Time) time.Time {
+	step := 0
+	switch rule {
+	case BusinessDayFollowing:
+		step = 1
+	case BusinessDayPreceding:
+		step = -1
+	}
+	for step != 0 && !IsBusinessDay(date, holidays) {
+		date = date.AddDate(0, 0, step)
+	}
+	return date
+}
+
+// ComputeDueDate returns DueDate when it is set, otherwise the due date of
+// the payment terms adjusted to a business day, or the zero time when the
+// invoice has neither.
+func (params *InvoiceParams) ComputeDueDate(holidays []time.Time) time.Time {
+	if !params.DueDate.IsZero() || params.PaymentTerms == "" {
+		return params.DueDate
+	}
+	terms, err := ParsePaymentTerms(params.PaymentTerms)
+	if err != nil {
+		return time.Time{}
+	}
+	return AdjustBusinessDay(terms.DueDate(params.Date), params.BusinessDay, holidays)
+}
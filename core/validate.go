@@ -0,0 +1,248 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// ValidationReport lists the problems found in params. Errors prevent a
+	// document from being generated, warnings only point at suspicious
+	// values.
+	ValidationReport struct {
+		Errors   FieldErrors
+		Warnings FieldErrors
+	}
+)
+
+func (r *ValidationReport) addError(path, format string, args ...any) {
+	r.Errors = append(r.Errors, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ValidationReport) addWarning(path, format string, args ...any) {
+	r.Warnings = append(r.Warnings, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// OK reports whether the report has no errors.
+func (r *ValidationReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns the errors of the report as FieldErrors, or nil when there are
+// none.
+func (r *ValidationReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return r.Errors
+}
+
+func (r *ValidationReport) requireString(path, value string) {
+	if value == "" {
+		r.addError(path, "is required")
+	}
+}
+
+func (r *ValidationReport) requireDate(path string, value time.Time) {
+	if value.IsZero() {
+		r.addError(path, "is required")
+	}
+}
+
+func (r *ValidationReport) checkCurrency(path, currency string) {
+	if currency == "" {
+		r.addError(path, "is required")
+	} else if !IsKnownCurrency(currency) {
+		r.addError(path, "unknown currency %q", currency)
+	}
+}
+
+func (r *ValidationReport) checkPeriod(startPath string, start time.Time, endPath string, end time.Time) {
+	if start.IsZero() || end.IsZero() {
+		if !start.IsZero() || !end.IsZero() {
+			r.addWarning(startPath, "period is incomplete")
+		}
+		return
+	}
+	if end.Before(start) {
+		r.addError(endPath, "%s is before %s", end.Format("2006/01/02"), start.Format("2006/01/02"))
+	}
+}
+
+func (r *ValidationReport) checkRate(path string, rate decimal.Decimal) {
+	if rate.IsNegative() {
+		r.addError(path, "rate %s must not be negative", rate)
+	} else if rate.GreaterThan(decimal.NewFromInt(1)) {
+		r.addError(path, "rate %s is above 1, rates are fractions such as 0.1", rate)
+	}
+}
+
+func (r *ValidationReport) checkDetailItems(items []InvoiceDetailItem, allowNegative bool) {
+	if len(items) == 0 {
+		r.addWarning("detail_items", "no detail items")
+	}
+	for ix, item := range items {
+		path := fmt.Sprintf("detail_items[%d]", ix)
+		if item.Title == "" {
+			r.addWarning(path+".title", "is empty")
+		}
+		r.checkRate(path+".tax_rate", item.TaxRate)
+		switch item.TaxCategory {
+		case "", TaxCategoryStandard, TaxCategoryZero, TaxCategoryExempt:
+		case TaxCategoryReduced:
+			// the summary rate is the standard one, never fall back to it
+			if item.TaxRate.IsZero() {
+				r.addError(path+".tax_rate", "is required for the reduced tax category")
+			}
+		default:
+			r.addError(path+".tax_category", "unknown tax category %q", item.TaxCategory)
+		}
+		if item.IsItemized() && item.Discount.IsPositive() && item.Discount.GreaterThan(item.Qty().Mul(item.UnitPrice)) {
+			r.addWarning(path+".discount", "discount exceeds the line amount")
+		}
+		if !allowNegative && item.LineTotal().IsNegative() {
+			r.addWarning(path, "line amount is negative")
+		}
+	}
+}
+
+func (r *ValidationReport) checkSummary(params *InvoiceParams) {
+	r.checkRate("summary.tax_rate", params.Summary.TaxRate)
+	switch params.Summary.Rounding {
+	case "", RoundingPerLine, RoundingPerDocument:
+	default:
+		r.addError("summary.rounding", "unknown rounding %q", params.Summary.Rounding)
+	}
+	// typed totals must add up whether or not they are computed
+	var mismatch *SummaryMismatchError
+	if err := params.CheckSummary(CurrencyPlaces(params.Currency)); errors.As(err, &mismatch) {
+		r.addError("summary."+mismatch.Field, "%s does not match %s computed from detail items", mismatch.Provided, mismatch.Computed)
+	}
+	if params.HasMixedTaxRates() && !params.HasItemAmounts() {
+		r.addError("detail_items", "items with mixed tax rates need amounts to total each rate")
+	}
+	if !params.Summary.Compute && params.Summary.TotalExcludeTax.IsZero() && params.Summary.TotalIncludeTax.IsZero() && len(params.DetailItems) > 0 {
+		r.addWarning("summary", "no totals given, set compute to derive them from detail items")
+	}
+}
+
+func (r *ValidationReport) checkPaymentTerms(params *InvoiceParams) {
+	if params.PaymentTerms != "" {
+		if _, err := ParsePaymentTerms(params.PaymentTerms); err != nil {
+			r.addError("payment_terms", "%v", err)
+		}
+	}
+	switch params.BusinessDay {
+	case "", BusinessDayFollowing, BusinessDayPreceding:
+	default:
+		r.addError("business_day", "unknown business day rule %q", params.BusinessDay)
+	}
+	switch params.Status {
+	case "", InvoiceStatusPaid, InvoiceStatusOverdue:
+	default:
+		r.addError("status", "unknown status %q", params.Status)
+	}
+	if !params.DueDate.IsZero() && params.DueDate.Before(params.Date) {
+		r.addError("due_date", "%s is before the invoice date", params.DueDate.Format("2006/01/02"))
+	}
+}
+
+// Validate checks the invoice params and reports errors and warnings by
+// YAML path.
+func (params *InvoiceParams) Validate() *ValidationReport {
+	r := &ValidationReport{}
+	r.requireString("id", params.ID)
+	r.requireDate("date", params.Date)
+	r.checkCurrency("currency", params.Currency)
+	r.requireString("company_name", params.CompanyName)
+	r.requireString("bill_to_company", params.BillToCompany)
+	r.checkPeriod("summary.period_start", params.Summary.PeriodStart, "summary.period_end", params.Summary.PeriodEnd)
+	r.checkSummary(params)
+	r.checkDetailItems(params.DetailItems, false)
+	r.checkPaymentTerms(params)
+	if params.QualifiedInvoice {
+		r.Errors = append(r.Errors, params.qualifiedInvoiceErrors()...)
+	}
+	return r
+}
+
+// Validate checks the quote params and reports errors and warnings by YAML
+// path.
+func (params *QuoteParams) Validate() *ValidationReport {
+	r := params.ToInvoiceParams(params.ID, params.Date).Validate()
+	r.requireDate("valid_until", params.ValidUntil)
+	if !params.ValidUntil.IsZero() && params.ValidUntil.Before(params.Date) {
+		r.addError("valid_until", "%s is before the quote date", params.ValidUntil.Format("2006/01/02"))
+	}
+	return r
+}
+
+// Validate checks the credit note params and reports errors and warnings by
+// YAML path.
+func (params *CreditNoteParams) Validate() *ValidationReport {
+	r := &ValidationReport{}
+	invoice := params.ToInvoiceParams()
+	r.requireString("id", params.ID)
+	r.requireDate("date", params.Date)
+	r.checkCurrency("currency", params.Currency)
+	r.requireString("company_name", params.CompanyName)
+	r.requireString("bill_to_company", params.BillToCompany)
+	r.requireString("original_invoice_id", params.OriginalInvoiceID)
+	r.checkSummary(invoice)
+	r.checkDetailItems(params.DetailItems, true)
+	if !params.OriginalInvoiceDate.IsZero() && params.Date.Before(params.OriginalInvoiceDate) {
+		r.addError("date", "credit note is dated before the original invoice")
+	}
+	return r
+}
+
+// Validate checks the receipt params and reports errors and warnings by YAML
+// path.
+func (params *ReceiptParams) Validate() *ValidationReport {
+	r := &ValidationReport{}
+	r.requireString("id", params.ID)
+	r.requireDate("date", params.Date)
+	r.checkCurrency("currency", params.Currency)
+	r.requireString("company_name", params.CompanyName)
+	r.requireString("received_from", params.ReceivedFrom)
+	r.checkRate("tax_rate", params.TaxRate)
+	if !params.Amount.IsPositive() {
+		r.addError("amount", "must be positive")
+	}
+	if params.Tax.IsNegative() || params.Tax.GreaterThan(params.Amount) {
+		r.addError("tax", "must be between 0 and the amount")
+	}
+	if params.For == "" {
+		r.addWarning("for", "is empty")
+	}
+	return r
+}
+
+// Validate checks the payment statement params and reports errors and
+// warnings by YAML path.
+func (params *PaymentStatementParams) Validate() *ValidationReport {
+	r := &ValidationReport{}
+	r.requireString("id", params.ID)
+	r.requireDate("date", params.Date)
+	r.checkCurrency("currency", params.Currency)
+	r.checkPeriod("period_start", params.PeriodStart, "period_end", params.PeriodEnd)
+	r.requireString("payer.name", params.Payer.Name)
+	r.requireString("payee.name", params.Payee.Name)
+	if len(params.DetailItems) == 0 {
+		r.addWarning("detail_items", "no detail items")
+	}
+	for ix, item := range params.DetailItems {
+		path := fmt.Sprintf("detail_items[%d]", ix)
+		if item.Title == "" {
+			r.addWarning(path+".title", "is empty")
+		}
+		if item.Amount.IsNegative() {
+			r.addWarning(path+".amount", "amount is negative")
+		}
+		r.checkRate(path+".withholding_tax_rate", item.WithholdingTaxRate)
+	}
+	return r
+}
@@ -0,0 +1,424 @@
+package einvoice
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/core"
+)
+
+// CIIProfile is a Factur-X / ZUGFeRD profile of the Cross Industry Invoice.
+type CIIProfile string
+
+const (
+	CIIMinimum CIIProfile = "minimum"
+	CIIBasic   CIIProfile = "basic"
+	CIIEN16931 CIIProfile = "en16931"
+)
+
+// CIIProfiles lists the supported Factur-X profiles, from the least to the
+// most detailed.
+var CIIProfiles = []CIIProfile{CIIMinimum, CIIBasic, CIIEN16931}
+
+const (
+	NamespaceRSM = "urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"
+	NamespaceRAM = "urn:un:unece:uncefact:data:standard:ReusableAggregateBusinessInformationEntity:100"
+	NamespaceUDT = "urn:un:unece:uncefact:data:standard:UnqualifiedDataType:100"
+	NamespaceQDT = "urn:un:unece:uncefact:data:standard:QualifiedDataType:100"
+
+	// FacturXFilename is the name the CII XML must be attached under.
+	FacturXFilename = "factur-x.xml"
+)
+
+var ibanPattern = regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{10,30}$`)
+
+// GuidelineID returns the specification identifier (BT-24) of the profile.
+func (p CIIProfile) GuidelineID() string {
+	switch p {
+	case CIIMinimum:
+		return "urn:factur-x.eu:1p0:minimum"
+	case CIIBasic:
+		return "urn:cen.eu:en16931:2017#compliant#urn:factur-x.eu:1p0:basic"
+	default:
+		return "urn:cen.eu:en16931:2017"
+	}
+}
+
+// ConformanceLevel returns the name of the profile in Factur-X XMP metadata.
+func (p CIIProfile) ConformanceLevel() string {
+	switch p {
+	case CIIMinimum:
+		return "MINIMUM"
+	case CIIBasic:
+		return "BASIC"
+	default:
+		return "EN 16931"
+	}
+}
+
+// CII D16B elements used by the Factur-X 1.0 profiles.
+type (
+	CrossIndustryInvoice struct {
+		XMLName  xml.Name `xml:"rsm:CrossIndustryInvoice"`
+		XMLNSRSM string   `xml:"xmlns:rsm,attr"`
+		XMLNSRAM string   `xml:"xmlns:ram,attr"`
+		XMLNSUDT string   `xml:"xmlns:udt,attr"`
+		XMLNSQDT string   `xml:"xmlns:qdt,attr"`
+
+		Context     CIIDocumentContext `xml:"rsm:ExchangedDocumentContext"`
+		Document    CIIDocument        `xml:"rsm:ExchangedDocument"`
+		Transaction CIITransaction     `xml:"rsm:SupplyChainTradeTransaction"`
+	}
+
+	CIIDocumentContext struct {
+		GuidelineID string `xml:"ram:GuidelineSpecifiedDocumentContextParameter>ram:ID"`
+	}
+
+	CIIDocument struct {
+		ID            string      `xml:"ram:ID"`
+		TypeCode      string      `xml:"ram:TypeCode"`
+		IssueDateTime CIIDateTime `xml:"ram:IssueDateTime"`
+		Note          string      `xml:"ram:IncludedNote>ram:Content,omitempty"`
+	}
+
+	CIIDateTime struct {
+		DateTimeString CIIDateTimeString `xml:"udt:DateTimeString"`
+	}
+
+	CIIDateTimeString struct {
+		Format string `xml:"format,attr"`
+		Value  string `xml:",chardata"`
+	}
+
+	CIITransaction struct {
+		LineItems  []CIILineItem       `xml:"ram:IncludedSupplyChainTradeLineItem"`
+		Agreement  CIIAgreement        `xml:"ram:ApplicableHeaderTradeAgreement"`
+		Delivery   struct{}            `xml:"ram:ApplicableHeaderTradeDelivery"`
+		Settlement CIIHeaderSettlement `xml:"ram:ApplicableHeaderTradeSettlement"`
+	}
+
+	CIIAgreement struct {
+		BuyerReference string   `xml:"ram:BuyerReference,omitempty"`
+		Seller         CIIParty `xml:"ram:SellerTradeParty"`
+		Buyer          CIIParty `xml:"ram:BuyerTradeParty"`
+		BuyerOrder     string   `xml:"ram:BuyerOrderReferencedDocument>ram:IssuerAssignedID,omitempty"`
+	}
+
+	CIIParty struct {
+		Name            string              `xml:"ram:Name"`
+		Address         *CIIAddress         `xml:"ram:PostalTradeAddress,omitempty"`
+		Email           string              `xml:"ram:URIUniversalCommunication>ram:URIID,omitempty"`
+		TaxRegistration *CIITaxRegistration `xml:"ram:SpecifiedTaxRegistration,omitempty"`
+	}
+
+	CIIAddress struct {
+		LineOne   string `xml:"ram:LineOne,omitempty"`
+		LineTwo   string `xml:"ram:LineTwo,omitempty"`
+		CountryID string `xml:"ram:CountryID"`
+	}
+
+	CIITaxRegistration struct {
+		ID CIIIdentifier `xml:"ram:ID"`
+	}
+
+	CIIIdentifier struct {
+		SchemeID string `xml:"schemeID,attr,omitempty"`
+		Value    string `xml:",chardata"`
+	}
+
+	CIIHeaderSettlement struct {
+		PaymentReference string             `xml:"ram:PaymentReference,omitempty"`
+		Currency         string             `xml:"ram:InvoiceCurrencyCode"`
+		PaymentMeans     *CIIPaymentMeans   `xml:"ram:SpecifiedTradeSettlementPaymentMeans,omitempty"`
+		Taxes            []CIITradeTax      `xml:"ram:ApplicableTradeTax"`
+		Period           *CIIPeriod         `xml:"ram:BillingSpecifiedPeriod,omitempty"`
+		PaymentTerms     *CIIPaymentTerms   `xml:"ram:SpecifiedTradePaymentTerms,omitempty"`
+		Summation        CIIHeaderSummation `xml:"ram:SpecifiedTradeSettlementHeaderMonetarySummation"`
+	}
+
+	CIIPaymentTerms struct {
+		Description string       `xml:"ram:Description,omitempty"`
+		DueDate     *CIIDateTime `xml:"ram:DueDateDateTime,omitempty"`
+	}
+
+	CIIPaymentMeans struct {
+		TypeCode    string               `xml:"ram:TypeCode"`
+		Account     *CIIFinancialAccount `xml:"ram:PayeePartyCreditorFinancialAccount,omitempty"`
+		Institution string               `xml:"ram:PayeeSpecifiedCreditorFinancialInstitution>ram:BICID,omitempty"`
+	}
+
+	CIIFinancialAccount struct {
+		IBANID        string `xml:"ram:IBANID,omitempty"`
+		AccountName   string `xml:"ram:AccountName,omitempty"`
+		ProprietaryID string `xml:"ram:ProprietaryID,omitempty"`
+	}
+
+	CIITradeTax struct {
+		CalculatedAmount string `xml:"ram:CalculatedAmount,omitempty"`
+		TypeCode         string `xml:"ram:TypeCode"`
+		ExemptionReason  string `xml:"ram:ExemptionReason,omitempty"`
+		BasisAmount      string `xml:"ram:BasisAmount,omitempty"`
+		CategoryCode     string `xml:"ram:CategoryCode"`
+		RatePercent      string `xml:"ram:RateApplicablePercent,omitempty"`
+	}
+
+	CIIPeriod struct {
+		Start *CIIDateTime `xml:"ram:StartDateTime,omitempty"`
+		End   *CIIDateTime `xml:"ram:EndDateTime,omitempty"`
+	}
+
+	CIIHeaderSummation struct {
+		LineTotal  string    `xml:"ram:LineTotalAmount,omitempty"`
+		TaxBasis   string    `xml:"ram:TaxBasisTotalAmount"`
+		TaxTotal   CIIAmount `xml:"ram:TaxTotalAmount"`
+		GrandTotal string    `xml:"ram:GrandTotalAmount"`
+		DuePayable string    `xml:"ram:DuePayableAmount"`
+	}
+
+	CIIAmount struct {
+		CurrencyID string `xml:"currencyID,attr"`
+		Value      string `xml:",chardata"`
+	}
+
+	CIILineItem struct {
+		LineID     string            `xml:"ram:AssociatedDocumentLineDocument>ram:LineID"`
+		Product    CIIProduct        `xml:"ram:SpecifiedTradeProduct"`
+		NetPrice   string            `xml:"ram:SpecifiedLineTradeAgreement>ram:NetPriceProductTradePrice>ram:ChargeAmount"`
+		Quantity   CIIQuantity       `xml:"ram:SpecifiedLineTradeDelivery>ram:BilledQuantity"`
+		Settlement CIILineSettlement `xml:"ram:SpecifiedLineTradeSettlement"`
+	}
+
+	CIIProduct struct {
+		Name        string `xml:"ram:Name"`
+		Description string `xml:"ram:Description,omitempty"`
+	}
+
+	CIIQuantity struct {
+		UnitCode string `xml:"unitCode,attr"`
+		Value    string `xml:",chardata"`
+	}
+
+	CIILineSettlement struct {
+		Tax       CIITradeTax         `xml:"ram:ApplicableTradeTax"`
+		Period    *CIIPeriod          `xml:"ram:BillingSpecifiedPeriod,omitempty"`
+		Allowance *CIIAllowanceCharge `xml:"ram:SpecifiedTradeAllowanceCharge,omitempty"`
+		LineTotal string              `xml:"ram:SpecifiedTradeSettlementLineMonetarySummation>ram:LineTotalAmount"`
+	}
+
+	CIIAllowanceCharge struct {
+		ChargeIndicator bool   `xml:"ram:ChargeIndicator>udt:Indicator"`
+		ActualAmount    string `xml:"ram:ActualAmount"`
+		Reason          string `xml:"ram:Reason,omitempty"`
+	}
+)
+
+// ValidateCII reports the business terms that the Factur-X profile makes
+// mandatory but the params do not carry.
+func ValidateCII(params *core.InvoiceParams, profile CIIProfile) *core.ValidationReport {
+	r := &core.ValidationReport{}
+	addError := func(path, bt, format string, args ...any) {
+		r.Errors = append(r.Errors, &core.FieldError{Path: path, Message: bt + " " + fmt.Sprintf(format, args...)})
+	}
+
+	if profile != CIIMinimum && profile != CIIBasic && profile != CIIEN16931 {
+		r.Errors = append(r.Errors, &core.FieldError{Message: fmt.Sprintf("unknown Factur-X profile %q", profile)})
+		return r
+	}
+
+	if params.ID == "" {
+		addError("id", "BT-1", "invoice number is required")
+	}
+	if params.Date.IsZero() {
+		addError("date", "BT-2", "issue date is required")
+	}
+	if !core.IsKnownCurrency(params.Currency) {
+		addError("currency", "BT-5", "invoice currency %q is not an ISO 4217 code", params.Currency)
+	}
+	if params.CompanyName == "" {
+		addError("company_name", "BT-27", "seller name is required")
+	}
+	if params.TaxNumber == "" {
+		addError("tax_number", "BT-31", "seller VAT identifier is required")
+	}
+	if !countryPattern.MatchString(params.CompanyCountry) {
+		addError("company_country", "BT-40", "seller country code is required")
+	}
+	if params.BillToCompany == "" {
+		addError("bill_to_company", "BT-44", "buyer name is required")
+	}
+	if profile == CIIMinimum {
+		return r
+	}
+
+	if !countryPattern.MatchString(params.BillToCountry) {
+		addError("bill_to_country", "BT-55", "buyer country code is required")
+	}
+	if len(params.DetailItems) == 0 {
+		addError("detail_items", "BG-25", "at least one invoice line is required")
+	}
+	for ix, item := range params.DetailItems {
+		path := fmt.Sprintf("detail_items[%d]", ix)
+		if item.Title == "" {
+			addError(path+".title", "BT-153", "item name is required")
+		}
+		if item.LineTotal().IsZero() && item.TotalIncludeTax.IsZero() {
+			addError(path, "BT-131", "invoice line net amount is required")
+		}
+	}
+	if computeTotals(params, "").GrandTotal().IsPositive() && params.DueDate.IsZero() && params.PaymentTerms == "" {
+		addError("due_date", "BR-CO-25", "a payment due date (BT-9) or payment terms (BT-20) are required when an amount is due")
+	}
+	return r
+}
+
+// MarshalCII validates the params and encodes them as a Cross Industry
+// Invoice of the Factur-X profile.
+func MarshalCII(params *core.InvoiceParams, profile CIIProfile) ([]byte, error) {
+	if err := ValidateCII(params, profile).Err(); err != nil {
+		return nil, err
+	}
+	buf, err := xml.MarshalIndent(NewCrossIndustryInvoice(params, profile), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(buf, '\n')...), nil
+}
+
+// NewCrossIndustryInvoice maps the params to a Cross Industry Invoice
+// without validating them, with the same amounts and due date as
+// NewInvoice.
+func NewCrossIndustryInvoice(params *core.InvoiceParams, profile CIIProfile) *CrossIndustryInvoice {
+	t := computeTotals(params, "")
+	detailed := profile != CIIMinimum
+
+	inv := &CrossIndustryInvoice{
+		XMLNSRSM: NamespaceRSM,
+		XMLNSRAM: NamespaceRAM,
+		XMLNSUDT: NamespaceUDT,
+		XMLNSQDT: NamespaceQDT,
+		Context:  CIIDocumentContext{GuidelineID: profile.GuidelineID()},
+		Document: CIIDocument{
+			ID:            params.ID,
+			TypeCode:      invoiceTypeCommercial,
+			IssueDateTime: ciiDate(params.Date),
+		},
+	}
+	if detailed {
+		inv.Document.Note = params.Summary.Title
+	}
+
+	inv.Transaction.Agreement = CIIAgreement{
+		BuyerReference: params.BuyerReference,
+		Seller: CIIParty{
+			Name:            params.CompanyName,
+			Address:         &CIIAddress{CountryID: params.CompanyCountry},
+			TaxRegistration: &CIITaxRegistration{ID: CIIIdentifier{SchemeID: "VA", Value: params.TaxNumber}},
+		},
+		Buyer:      CIIParty{Name: params.BillToCompany},
+		BuyerOrder: params.OrderReference,
+	}
+	if detailed {
+		seller := &inv.Transaction.Agreement.Seller
+		seller.Address.LineOne, seller.Address.LineTwo = addressLines(params.CompanyAddr)
+		buyer := &inv.Transaction.Agreement.Buyer
+		buyer.Address = &CIIAddress{CountryID: params.BillToCountry}
+		buyer.Address.LineOne, buyer.Address.LineTwo = addressLines(params.BillToAddress)
+		if params.BillToTaxNumber != "" {
+			buyer.TaxRegistration = &CIITaxRegistration{ID: CIIIdentifier{SchemeID: "VA", Value: params.BillToTaxNumber}}
+		}
+		if profile == CIIEN16931 && params.CompanyEmail != "" {
+			seller.Email = params.CompanyEmail
+		}
+	}
+
+	settlement := &inv.Transaction.Settlement
+	settlement.Currency = t.Currency
+	settlement.Summation = CIIHeaderSummation{
+		TaxBasis:   t.Format(t.LineTotal),
+		TaxTotal:   CIIAmount{CurrencyID: t.Currency, Value: t.Format(t.TaxTotal)},
+		GrandTotal: t.Format(t.GrandTotal()),
+		DuePayable: t.Format(t.GrandTotal()),
+	}
+	if !detailed {
+		return inv
+	}
+
+	settlement.Summation.LineTotal = t.Format(t.LineTotal)
+	for _, g := range t.Groups {
+		settlement.Taxes = append(settlement.Taxes, CIITradeTax{
+			CalculatedAmount: t.Format(g.Tax),
+			TypeCode:         "VAT",
+			ExemptionReason:  g.Category.ExemptionReason,
+			BasisAmount:      t.Format(g.Taxable),
+			CategoryCode:     g.Category.ID,
+			RatePercent:      g.Category.Percent,
+		})
+	}
+	if !params.Summary.PeriodStart.IsZero() && !params.Summary.PeriodEnd.IsZero() {
+		start, end := ciiDate(params.Summary.PeriodStart), ciiDate(params.Summary.PeriodEnd)
+		settlement.Period = &CIIPeriod{Start: &start, End: &end}
+	}
+	if due := params.ComputeDueDate(nil); !due.IsZero() || params.PaymentTerms != "" {
+		settlement.PaymentTerms = &CIIPaymentTerms{Description: params.PaymentTerms}
+		if !due.IsZero() {
+			date := ciiDate(due)
+			settlement.PaymentTerms.DueDate = &date
+		}
+	}
+	if payment := params.Payment; !payment.Disabled && payment.ReceiveAccountNumber != "" {
+		settlement.PaymentReference = payment.PaymentID
+		account := &CIIFinancialAccount{ProprietaryID: payment.ReceiveAccountNumber}
+		if ibanPattern.MatchString(payment.ReceiveAccountNumber) {
+			account = &CIIFinancialAccount{IBANID: payment.ReceiveAccountNumber}
+		}
+		settlement.PaymentMeans = &CIIPaymentMeans{TypeCode: paymentMeansCreditTransfer, Account: account}
+		if profile == CIIEN16931 {
+			account.AccountName = payment.ReceiveAccountName
+			settlement.PaymentMeans.Institution = payment.ReceiveAccountSwift
+		}
+	}
+
+	for ix, line := range t.Lines {
+		item := line.Item
+		lineItem := CIILineItem{
+			LineID:   fmt.Sprintf("%d", ix+1),
+			Product:  CIIProduct{Name: item.Title},
+			NetPrice: t.Format(line.Net),
+			Quantity: CIIQuantity{UnitCode: unitCode(item.Unit), Value: "1"},
+			Settlement: CIILineSettlement{
+				Tax: CIITradeTax{
+					TypeCode:     "VAT",
+					CategoryCode: line.Category.ID,
+					RatePercent:  line.Category.Percent,
+				},
+				LineTotal: t.Format(line.Net),
+			},
+		}
+		if profile == CIIEN16931 {
+			lineItem.Product.Description = item.Desc
+			if !item.Date.IsZero() {
+				date := ciiDate(item.Date)
+				lineItem.Settlement.Period = &CIIPeriod{Start: &date, End: &date}
+			}
+		}
+		if item.IsItemized() {
+			lineItem.NetPrice = item.UnitPrice.String()
+			lineItem.Quantity.Value = item.Qty().String()
+			if !item.Discount.IsZero() {
+				lineItem.Settlement.Allowance = &CIIAllowanceCharge{
+					ChargeIndicator: false,
+					ActualAmount:    t.Format(item.Discount),
+					Reason:          "Discount",
+				}
+			}
+		}
+		inv.Transaction.LineItems = append(inv.Transaction.LineItems, lineItem)
+	}
+	return inv
+}
+
+func ciiDate(t time.Time) CIIDateTime {
+	return CIIDateTime{DateTimeString: CIIDateTimeString{Format: "102", Value: t.Format("20060102")}}
+}
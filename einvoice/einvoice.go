@@ -0,0 +1,316 @@
+// Package einvoice exports invoice params as structured e-invoices in UBL
+// 2.1 syntax, following Peppol BIS Billing 3.0 or JP PINT.
+package einvoice
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+type Profile string
+
+const (
+	ProfilePeppolBIS Profile = "peppol"
+	ProfileJPPINT    Profile = "jp-pint"
+)
+
+// Profiles lists the supported e-invoice profiles.
+var Profiles = []Profile{ProfilePeppolBIS, ProfileJPPINT}
+
+const (
+	peppolProfileID       = "urn:fdc:peppol.eu:2017:poacc:billing:01:1.0"
+	peppolCustomizationID = "urn:cen.eu:en16931:2017#compliant#urn:fdc:peppol.eu:2017:poacc:billing:3.0"
+	jpPINTCustomizationID = "urn:peppol:pint:billing-1@jp-1"
+
+	// invoiceTypeCommercial is the UNTDID 1001 code of a commercial invoice.
+	invoiceTypeCommercial = "380"
+	// paymentMeansCreditTransfer is the UNTDID 4461 code of a credit
+	// transfer.
+	paymentMeansCreditTransfer = "30"
+	// unitCodeOne is the UN/ECE Recommendation 20 code of a unit without
+	// dimension.
+	unitCodeOne = "C62"
+)
+
+var (
+	countryPattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	endpointPattern = regexp.MustCompile(`^\d{4}:\S+$`)
+
+	// unitCodes maps common units of detail items to UN/ECE Recommendation
+	// 20 codes.
+	unitCodes = map[string]string{
+		"h": "HUR", "hr": "HUR", "hrs": "HUR", "hour": "HUR", "hours": "HUR", "時間": "HUR",
+		"day": "DAY", "days": "DAY", "日": "DAY",
+		"month": "MON", "months": "MON", "ヶ月": "MON", "か月": "MON",
+		"pc": "H87", "pcs": "H87", "piece": "H87", "pieces": "H87", "個": "H87",
+		"kg": "KGM", "m": "MTR",
+	}
+)
+
+// Validate reports the business terms that the profile makes mandatory but
+// the params do not carry. Errors name the business term, e.g. "BT-34
+// seller electronic address is required".
+func Validate(params *core.InvoiceParams, profile Profile) *core.ValidationReport {
+	r := &core.ValidationReport{}
+	addError := func(path, bt, format string, args ...any) {
+		r.Errors = append(r.Errors, &core.FieldError{Path: path, Message: bt + " " + fmt.Sprintf(format, args...)})
+	}
+
+	if profile != ProfilePeppolBIS && profile != ProfileJPPINT {
+		r.Errors = append(r.Errors, &core.FieldError{Message: fmt.Sprintf("unknown profile %q", profile)})
+		return r
+	}
+
+	if params.ID == "" {
+		addError("id", "BT-1", "invoice number is required")
+	}
+	if params.Date.IsZero() {
+		addError("date", "BT-2", "issue date is required")
+	}
+	if !core.IsKnownCurrency(params.Currency) {
+		addError("currency", "BT-5", "invoice currency %q is not an ISO 4217 code", params.Currency)
+	}
+
+	if params.CompanyName == "" {
+		addError("company_name", "BT-27", "seller name is required")
+	}
+	if params.TaxNumber == "" {
+		addError("tax_number", "BT-31", "seller VAT identifier is required")
+	} else if profile == ProfileJPPINT && !core.IsRegistrationNumber(params.TaxNumber) {
+		addError("tax_number", "IBT-031", "%q is not a registration number of the form T followed by 13 digits", params.TaxNumber)
+	}
+	if !endpointPattern.MatchString(params.CompanyEndpoint) {
+		addError("company_endpoint", "BT-34", "seller electronic address of the form scheme:identifier is required")
+	}
+	if !countryPattern.MatchString(params.CompanyCountry) {
+		addError("company_country", "BT-40", "seller country code is required")
+	}
+
+	if params.BillToCompany == "" {
+		addError("bill_to_company", "BT-44", "buyer name is required")
+	}
+	if !endpointPattern.MatchString(params.BillToEndpoint) {
+		addError("bill_to_endpoint", "BT-49", "buyer electronic address of the form scheme:identifier is required")
+	}
+	if !countryPattern.MatchString(params.BillToCountry) {
+		addError("bill_to_country", "BT-55", "buyer country code is required")
+	}
+
+	if len(params.DetailItems) == 0 {
+		addError("detail_items", "BG-25", "at least one invoice line is required")
+	}
+	for ix, item := range params.DetailItems {
+		path := fmt.Sprintf("detail_items[%d]", ix)
+		if item.Title == "" {
+			addError(path+".title", "BT-153", "item name is required")
+		}
+		if item.LineTotal().IsZero() && item.TotalIncludeTax.IsZero() {
+			addError(path, "BT-131", "invoice line net amount is required")
+		}
+		if item.IsItemized() && item.Qty().IsNegative() {
+			addError(path+".quantity", "BT-129", "invoiced quantity must not be negative on an invoice")
+		}
+	}
+
+	if profile == ProfilePeppolBIS && params.BuyerReference == "" && params.OrderReference == "" {
+		addError("buyer_reference", "PEPPOL-EN16931-R003", "a buyer reference (BT-10) or an order reference (BT-13) is required")
+	}
+	if computeTotals(params, profile).GrandTotal().IsPositive() && params.DueDate.IsZero() && params.PaymentTerms == "" {
+		addError("due_date", "BR-CO-25", "a payment due date (BT-9) or payment terms (BT-20) are required when an amount is due")
+	}
+
+	if !params.Payment.Disabled && params.Payment.ReceiveAccountNumber == "" {
+		r.Warnings = append(r.Warnings, &core.FieldError{Path: "payment.receive_account_number", Message: "BT-84 payment account identifier is missing, no payment means are exported"})
+	}
+	return r
+}
+
+// Marshal validates the params and encodes them as a UBL 2.1 invoice.
+func Marshal(params *core.InvoiceParams, profile Profile) ([]byte, error) {
+	if err := Validate(params, profile).Err(); err != nil {
+		return nil, err
+	}
+	buf, err := xml.MarshalIndent(NewInvoice(params, profile), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(buf, '\n')...), nil
+}
+
+// NewInvoice maps the params to a UBL invoice without validating them.
+// Amounts are recomputed from the detail items and rounded to the places of
+// the currency, so the totals satisfy the EN 16931 calculation rules. The
+// due date is computed from the payment terms when params have none, moved
+// off weekends only; set DueDate to account for holidays.
+func NewInvoice(params *core.InvoiceParams, profile Profile) *Invoice {
+	currency := core.ISOCurrency(params.Currency)
+	places := core.CurrencyPlaces(params.Currency)
+	amount := func(d decimal.Decimal) Amount {
+		return Amount{CurrencyID: currency, Value: d.StringFixed(places)}
+	}
+
+	inv := &Invoice{
+		XMLNS:                NamespaceInvoice,
+		XMLNSCAC:             NamespaceCAC,
+		XMLNSCBC:             NamespaceCBC,
+		CustomizationID:      peppolCustomizationID,
+		ProfileID:            peppolProfileID,
+		ID:                   params.ID,
+		IssueDate:            formatTime(params.Date),
+		InvoiceTypeCode:      invoiceTypeCommercial,
+		Note:                 params.Summary.Title,
+		DocumentCurrencyCode: currency,
+		BuyerReference:       params.BuyerReference,
+		Supplier: SupplierParty{Party: newParty(
+			params.CompanyName, params.CompanyAddr, params.CompanyCountry,
+			params.CompanyEndpoint, params.TaxNumber, params.CompanyEmail,
+		)},
+		Customer: CustomerParty{Party: newParty(
+			params.BillToCompany, params.BillToAddress, params.BillToCountry,
+			params.BillToEndpoint, params.BillToTaxNumber, "",
+		)},
+	}
+	if profile == ProfileJPPINT {
+		inv.CustomizationID = jpPINTCustomizationID
+	}
+	if !params.Summary.PeriodStart.IsZero() || !params.Summary.PeriodEnd.IsZero() {
+		inv.InvoicePeriod = &Period{
+			StartDate: formatTime(params.Summary.PeriodStart),
+			EndDate:   formatTime(params.Summary.PeriodEnd),
+		}
+	}
+
+	if params.OrderReference != "" {
+		inv.OrderReference = &OrderReference{ID: params.OrderReference}
+	}
+	if due := params.ComputeDueDate(nil); !due.IsZero() {
+		inv.DueDate = formatTime(due)
+	}
+	if params.PaymentTerms != "" {
+		inv.PaymentTerms = &PaymentTerms{Note: params.PaymentTerms}
+	}
+
+	if payment := params.Payment; !payment.Disabled && payment.ReceiveAccountNumber != "" {
+		inv.PaymentMeans = &PaymentMeans{
+			PaymentMeansCode: paymentMeansCreditTransfer,
+			PaymentID:        payment.PaymentID,
+			PayeeFinancialAccount: &FinancialAccount{
+				ID:   payment.ReceiveAccountNumber,
+				Name: payment.ReceiveAccountName,
+			},
+		}
+		branch := payment.ReceiveAccountSwift
+		if branch == "" {
+			branch = payment.ReceiveAccountRouting
+		}
+		if branch != "" {
+			inv.PaymentMeans.PayeeFinancialAccount.FinancialInstitutionBranch = &FinancialInstitutionBranch{ID: branch}
+		}
+	}
+
+	t := computeTotals(params, profile)
+	for ix, line := range t.Lines {
+		item := line.Item
+		ublLine := InvoiceLine{
+			ID:                  fmt.Sprintf("%d", ix+1),
+			InvoicedQuantity:    Quantity{UnitCode: unitCode(item.Unit), Value: "1"},
+			LineExtensionAmount: amount(line.Net),
+			Item: Item{
+				Name:        item.Title,
+				Description: item.Desc,
+				ClassifiedTaxCategory: TaxCategory{
+					ID:        line.Category.ID,
+					Percent:   line.Category.Percent,
+					TaxScheme: TaxScheme{ID: "VAT"},
+				},
+			},
+			Price: Price{PriceAmount: amount(line.Net)},
+		}
+		if item.IsItemized() {
+			ublLine.InvoicedQuantity.Value = item.Qty().String()
+			ublLine.Price.PriceAmount = Amount{CurrencyID: currency, Value: item.UnitPrice.String()}
+			if !item.Discount.IsZero() {
+				ublLine.AllowanceCharge = &AllowanceCharge{
+					ChargeIndicator:       false,
+					AllowanceChargeReason: "Discount",
+					Amount:                amount(item.Discount),
+				}
+			}
+		}
+		if !item.Date.IsZero() {
+			ublLine.InvoicePeriod = &Period{StartDate: formatTime(item.Date), EndDate: formatTime(item.Date)}
+		}
+		inv.InvoiceLines = append(inv.InvoiceLines, ublLine)
+	}
+
+	inv.TaxTotal = TaxTotal{TaxAmount: amount(t.TaxTotal)}
+	for _, g := range t.Groups {
+		inv.TaxTotal.TaxSubtotals = append(inv.TaxTotal.TaxSubtotals, TaxSubtotal{
+			TaxableAmount: amount(g.Taxable),
+			TaxAmount:     amount(g.Tax),
+			TaxCategory: TaxCategory{
+				ID:                 g.Category.ID,
+				Percent:            g.Category.Percent,
+				TaxExemptionReason: g.Category.ExemptionReason,
+				TaxScheme:          TaxScheme{ID: "VAT"},
+			},
+		})
+	}
+	inv.LegalMonetaryTotal = MonetaryTotal{
+		LineExtensionAmount: amount(t.LineTotal),
+		TaxExclusiveAmount:  amount(t.LineTotal),
+		TaxInclusiveAmount:  amount(t.GrandTotal()),
+		PayableAmount:       amount(t.GrandTotal()),
+	}
+	return inv
+}
+
+func newParty(name, address, country, endpoint, taxNumber, email string) Party {
+	party := Party{
+		PartyName:        &PartyName{Name: name},
+		PartyLegalEntity: PartyLegalEntity{RegistrationName: name},
+		PostalAddress:    Address{Country: Country{IdentificationCode: country}},
+	}
+	if scheme, id, ok := strings.Cut(endpoint, ":"); ok {
+		party.EndpointID = &Identifier{SchemeID: scheme, Value: id}
+	}
+	party.PostalAddress.StreetName, party.PostalAddress.AdditionalStreetName = addressLines(address)
+	if taxNumber != "" {
+		party.PartyTaxScheme = &PartyTaxScheme{CompanyID: taxNumber, TaxScheme: TaxScheme{ID: "VAT"}}
+	}
+	if email != "" {
+		party.Contact = &Contact{ElectronicMail: email}
+	}
+	return party
+}
+
+// addressLines splits a multi-line address into its first line and the
+// remaining lines joined with commas.
+func addressLines(address string) (string, string) {
+	lines := strings.Split(strings.TrimSpace(address), "\n")
+	rest := make([]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		rest = append(rest, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(lines[0]), strings.Join(rest, ", ")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func unitCode(unit string) string {
+	if code, ok := unitCodes[strings.ToLower(strings.TrimSpace(unit))]; ok {
+		return code
+	}
+	return unitCodeOne
+}
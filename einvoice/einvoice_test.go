@@ -0,0 +1,154 @@
+package einvoice
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quail-ink/bizdocgen/core"
+)
+
+func TestMarshal(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../sample-params/invoice-4.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := Marshal(params, ProfileJPPINT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<cbc:CustomizationID>urn:peppol:pint:billing-1@jp-1</cbc:CustomizationID>",
+		`<cbc:EndpointID schemeID="0188">1234567890123</cbc:EndpointID>`,
+		"<cbc:CompanyID>T1234567890123</cbc:CompanyID>",
+		"<cbc:ID>AA</cbc:ID>",
+		`<cbc:TaxAmount currencyID="JPY">5240</cbc:TaxAmount>`,
+		`<cbc:PayableAmount currencyID="JPY">74540</cbc:PayableAmount>`,
+		"<cbc:BuyerReference>PO-2024-0042</cbc:BuyerReference>",
+		"<cbc:DueDate>2024-06-28</cbc:DueDate>",
+		"<cbc:Note>月末締め翌月末払い</cbc:Note>",
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the XML to contain %s", want)
+		}
+	}
+
+	inv := NewInvoice(params, ProfilePeppolBIS)
+	if inv.CustomizationID != peppolCustomizationID {
+		t.Fatalf("unexpected customization %s", inv.CustomizationID)
+	}
+	for _, subtotal := range inv.TaxTotal.TaxSubtotals {
+		if subtotal.TaxCategory.ID == "AA" {
+			t.Fatal("Peppol BIS invoices must not use the AA category")
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../sample-params/invoice-3.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Validate(params, ProfilePeppolBIS)
+	missing := map[string]bool{}
+	for _, err := range report.Errors {
+		missing[err.Path] = true
+	}
+	for _, path := range []string{"company_endpoint", "company_country", "bill_to_endpoint", "bill_to_country"} {
+		if !missing[path] {
+			t.Fatalf("expected %s to be reported, got %v", path, report.Errors)
+		}
+	}
+
+	if _, err := Marshal(params, ProfilePeppolBIS); err == nil {
+		t.Fatal("expected incomplete params to be refused")
+	}
+
+	params.TaxNumber = "123"
+	params.CompanyEndpoint, params.CompanyCountry = "0088:4012345000009", "US"
+	params.BillToEndpoint, params.BillToCountry = "0088:4012345000016", "US"
+	report = Validate(params, ProfilePeppolBIS)
+	missing = map[string]bool{}
+	for _, err := range report.Errors {
+		missing[err.Path] = true
+	}
+	if !missing["buyer_reference"] || !missing["due_date"] {
+		t.Fatalf("expected PEPPOL-EN16931-R003 and BR-CO-25 to be reported, got %v", report.Errors)
+	}
+
+	// either reference satisfies R003, either the due date or the terms
+	// BR-CO-25
+	params.OrderReference = "PO-1"
+	params.DueDate = params.Date.AddDate(0, 0, 30)
+	if report := Validate(params, ProfilePeppolBIS); !report.OK() {
+		t.Fatalf("expected no errors, got %v", report.Errors)
+	}
+	inv := NewInvoice(params, ProfilePeppolBIS)
+	if inv.OrderReference == nil || inv.OrderReference.ID != "PO-1" || inv.DueDate != formatTime(params.DueDate) {
+		t.Fatalf("expected the order reference and due date to be exported, got %+v %s", inv.OrderReference, inv.DueDate)
+	}
+	params.OrderReference, params.BuyerReference = "", "Dept 7"
+	params.DueDate, params.PaymentTerms = time.Time{}, "net-30"
+	if report := Validate(params, ProfilePeppolBIS); !report.OK() {
+		t.Fatalf("expected no errors, got %v", report.Errors)
+	}
+	if report := Validate(params, ProfileJPPINT); report.OK() || report.Errors[0].Path != "tax_number" {
+		t.Fatalf("expected the registration number to be reported, got %v", report.Errors)
+	}
+}
+
+func TestMarshalCII(t *testing.T) {
+	params := &core.InvoiceParams{}
+	if err := params.Load("../sample-params/invoice-4.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := MarshalCII(params, CIIEN16931)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<ram:ID>urn:cen.eu:en16931:2017</ram:ID>",
+		`<udt:DateTimeString format="102">20240510</udt:DateTimeString>`,
+		"<ram:InvoiceCurrencyCode>JPY</ram:InvoiceCurrencyCode>",
+		`<ram:TaxTotalAmount currencyID="JPY">5240</ram:TaxTotalAmount>`,
+		"<ram:DuePayableAmount>74540</ram:DuePayableAmount>",
+		"<ram:BuyerReference>PO-2024-0042</ram:BuyerReference>",
+		"<ram:Description>月末締め翌月末払い</ram:Description>",
+	} {
+		if !strings.Contains(string(buf), want) {
+			t.Fatalf("expected the XML to contain %s", want)
+		}
+	}
+	if terms, summation := strings.Index(string(buf), "<ram:SpecifiedTradePaymentTerms>"), strings.Index(string(buf), "<ram:SpecifiedTradeSettlementHeaderMonetarySummation>"); terms < 0 || terms > summation {
+		t.Fatal("expected the payment terms before the monetary summation")
+	}
+
+	// the end of June 2024 is a Sunday, moved to the preceding Friday
+	if terms := NewCrossIndustryInvoice(params, CIIBasic).Transaction.Settlement.PaymentTerms; terms == nil || terms.DueDate == nil || terms.DueDate.DateTimeString.Value != "20240628" {
+		t.Fatalf("expected the due date computed from the terms, got %+v", terms)
+	}
+
+	params.PaymentTerms = ""
+	if report := ValidateCII(params, CIIBasic); report.OK() || report.Errors[0].Path != "due_date" {
+		t.Fatalf("expected BR-CO-25 to be reported, got %v", report.Errors)
+	}
+	if report := ValidateCII(params, CIIMinimum); !report.OK() {
+		t.Fatalf("expected MINIMUM to accept params without payment terms, got %v", report.Errors)
+	}
+	params.DueDate = time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)
+	inv := NewCrossIndustryInvoice(params, CIIEN16931)
+	if terms := inv.Transaction.Settlement.PaymentTerms; terms == nil || terms.DueDate == nil || terms.DueDate.DateTimeString.Value != "20240628" {
+		t.Fatalf("expected the due date to be exported, got %+v", terms)
+	}
+
+	params.BillToCountry = ""
+	if report := ValidateCII(params, CIIMinimum); !report.OK() {
+		t.Fatalf("expected MINIMUM to accept params without buyer country, got %v", report.Errors)
+	}
+	if report := ValidateCII(params, CIIBasic); report.OK() || report.Errors[0].Path != "bill_to_country" {
+		t.Fatalf("expected the buyer country to be reported, got %v", report.Errors)
+	}
+}
@@ -0,0 +1,106 @@
+package einvoice
+
+import (
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+type (
+	// taxCategory is a UNCL 5305 tax category with its rate in percent.
+	taxCategory struct {
+		ID              string
+		Percent         string
+		ExemptionReason string
+	}
+
+	lineAmounts struct {
+		Item     *core.InvoiceDetailItem
+		Net      decimal.Decimal
+		Category taxCategory
+	}
+
+	taxGroup struct {
+		Category taxCategory
+		Taxable  decimal.Decimal
+		Tax      decimal.Decimal
+	}
+
+	// totals are the amounts of an invoice computed following the EN 16931
+	// calculation rules: line net amounts are rounded first, and the tax of
+	// each category is computed once from the sum of its lines.
+	totals struct {
+		Currency  string
+		Places    int32
+		Lines     []lineAmounts
+		Groups    []*taxGroup
+		LineTotal decimal.Decimal
+		TaxTotal  decimal.Decimal
+	}
+)
+
+func computeTotals(params *core.InvoiceParams, profile Profile) *totals {
+	t := &totals{
+		Currency: core.ISOCurrency(params.Currency),
+		Places:   core.CurrencyPlaces(params.Currency),
+	}
+	for ix := range params.DetailItems {
+		item := &params.DetailItems[ix]
+		net, _ := params.ItemAmounts(item)
+		net = net.Round(t.Places)
+		category := itemTaxCategory(params, item, profile)
+		t.Lines = append(t.Lines, lineAmounts{Item: item, Net: net, Category: category})
+		t.LineTotal = t.LineTotal.Add(net)
+
+		var group *taxGroup
+		for _, g := range t.Groups {
+			if g.Category == category {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = &taxGroup{Category: category}
+			t.Groups = append(t.Groups, group)
+		}
+		group.Taxable = group.Taxable.Add(net)
+	}
+
+	for _, g := range t.Groups {
+		rate, _ := decimal.NewFromString(g.Category.Percent)
+		g.Tax = g.Taxable.Mul(rate).Div(decimal.NewFromInt(100)).Round(t.Places)
+		t.TaxTotal = t.TaxTotal.Add(g.Tax)
+	}
+	return t
+}
+
+// GrandTotal returns the total including tax.
+func (t *totals) GrandTotal() decimal.Decimal {
+	return t.LineTotal.Add(t.TaxTotal)
+}
+
+// Format formats an amount with the places of the currency.
+func (t *totals) Format(d decimal.Decimal) string {
+	return d.StringFixed(t.Places)
+}
+
+// itemTaxCategory returns the tax category of the item. JP PINT
+// distinguishes the reduced rate with "AA", the EN 16931 profiles only by
+// percent.
+func itemTaxCategory(params *core.InvoiceParams, item *core.InvoiceDetailItem, profile Profile) taxCategory {
+	category := taxCategory{
+		ID:      "S",
+		Percent: params.ItemTaxRate(item).Mul(decimal.NewFromInt(100)).String(),
+	}
+	switch item.TaxCategory {
+	case core.TaxCategoryZero:
+		category.ID, category.Percent = "Z", "0"
+	case core.TaxCategoryExempt:
+		category.ID, category.Percent = "E", "0"
+		category.ExemptionReason = "Exempt"
+	case core.TaxCategoryReduced:
+		if profile == ProfileJPPINT {
+			category.ID = "AA"
+		}
+	}
+	return category
+}
@@ -0,0 +1,183 @@
+package einvoice
+
+import "encoding/xml"
+
+const (
+	NamespaceInvoice = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	NamespaceCAC     = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	NamespaceCBC     = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+)
+
+// UBL 2.1 elements used by Peppol BIS Billing 3.0 and JP PINT invoices. Only
+// the business terms bizdocgen params can carry are modelled.
+type (
+	Invoice struct {
+		XMLName  xml.Name `xml:"Invoice"`
+		XMLNS    string   `xml:"xmlns,attr"`
+		XMLNSCAC string   `xml:"xmlns:cac,attr"`
+		XMLNSCBC string   `xml:"xmlns:cbc,attr"`
+
+		CustomizationID      string          `xml:"cbc:CustomizationID"`
+		ProfileID            string          `xml:"cbc:ProfileID"`
+		ID                   string          `xml:"cbc:ID"`
+		IssueDate            string          `xml:"cbc:IssueDate"`
+		DueDate              string          `xml:"cbc:DueDate,omitempty"`
+		InvoiceTypeCode      string          `xml:"cbc:InvoiceTypeCode"`
+		Note                 string          `xml:"cbc:Note,omitempty"`
+		DocumentCurrencyCode string          `xml:"cbc:DocumentCurrencyCode"`
+		BuyerReference       string          `xml:"cbc:BuyerReference,omitempty"`
+		InvoicePeriod        *Period         `xml:"cac:InvoicePeriod,omitempty"`
+		OrderReference       *OrderReference `xml:"cac:OrderReference,omitempty"`
+		Supplier             SupplierParty   `xml:"cac:AccountingSupplierParty"`
+		Customer             CustomerParty   `xml:"cac:AccountingCustomerParty"`
+		PaymentMeans         *PaymentMeans   `xml:"cac:PaymentMeans,omitempty"`
+		PaymentTerms         *PaymentTerms   `xml:"cac:PaymentTerms,omitempty"`
+		TaxTotal             TaxTotal        `xml:"cac:TaxTotal"`
+		LegalMonetaryTotal   MonetaryTotal   `xml:"cac:LegalMonetaryTotal"`
+		InvoiceLines         []InvoiceLine   `xml:"cac:InvoiceLine"`
+	}
+
+	Amount struct {
+		CurrencyID string `xml:"currencyID,attr"`
+		Value      string `xml:",chardata"`
+	}
+
+	Quantity struct {
+		UnitCode string `xml:"unitCode,attr"`
+		Value    string `xml:",chardata"`
+	}
+
+	Identifier struct {
+		SchemeID string `xml:"schemeID,attr,omitempty"`
+		Value    string `xml:",chardata"`
+	}
+
+	Period struct {
+		StartDate string `xml:"cbc:StartDate,omitempty"`
+		EndDate   string `xml:"cbc:EndDate,omitempty"`
+	}
+
+	OrderReference struct {
+		ID string `xml:"cbc:ID"`
+	}
+
+	SupplierParty struct {
+		Party Party `xml:"cac:Party"`
+	}
+
+	CustomerParty struct {
+		Party Party `xml:"cac:Party"`
+	}
+
+	Party struct {
+		EndpointID       *Identifier      `xml:"cbc:EndpointID,omitempty"`
+		PartyName        *PartyName       `xml:"cac:PartyName,omitempty"`
+		PostalAddress    Address          `xml:"cac:PostalAddress"`
+		PartyTaxScheme   *PartyTaxScheme  `xml:"cac:PartyTaxScheme,omitempty"`
+		PartyLegalEntity PartyLegalEntity `xml:"cac:PartyLegalEntity"`
+		Contact          *Contact         `xml:"cac:Contact,omitempty"`
+	}
+
+	PartyName struct {
+		Name string `xml:"cbc:Name"`
+	}
+
+	Address struct {
+		StreetName           string  `xml:"cbc:StreetName,omitempty"`
+		AdditionalStreetName string  `xml:"cbc:AdditionalStreetName,omitempty"`
+		Country              Country `xml:"cac:Country"`
+	}
+
+	Country struct {
+		IdentificationCode string `xml:"cbc:IdentificationCode"`
+	}
+
+	PartyTaxScheme struct {
+		CompanyID string    `xml:"cbc:CompanyID"`
+		TaxScheme TaxScheme `xml:"cac:TaxScheme"`
+	}
+
+	TaxScheme struct {
+		ID string `xml:"cbc:ID"`
+	}
+
+	PartyLegalEntity struct {
+		RegistrationName string `xml:"cbc:RegistrationName"`
+	}
+
+	Contact struct {
+		ElectronicMail string `xml:"cbc:ElectronicMail,omitempty"`
+	}
+
+	PaymentMeans struct {
+		PaymentMeansCode      string            `xml:"cbc:PaymentMeansCode"`
+		PaymentID             string            `xml:"cbc:PaymentID,omitempty"`
+		PayeeFinancialAccount *FinancialAccount `xml:"cac:PayeeFinancialAccount,omitempty"`
+	}
+
+	PaymentTerms struct {
+		Note string `xml:"cbc:Note"`
+	}
+
+	FinancialAccount struct {
+		ID                         string                      `xml:"cbc:ID"`
+		Name                       string                      `xml:"cbc:Name,omitempty"`
+		FinancialInstitutionBranch *FinancialInstitutionBranch `xml:"cac:FinancialInstitutionBranch,omitempty"`
+	}
+
+	FinancialInstitutionBranch struct {
+		ID string `xml:"cbc:ID"`
+	}
+
+	TaxTotal struct {
+		TaxAmount    Amount        `xml:"cbc:TaxAmount"`
+		TaxSubtotals []TaxSubtotal `xml:"cac:TaxSubtotal"`
+	}
+
+	TaxSubtotal struct {
+		TaxableAmount Amount      `xml:"cbc:TaxableAmount"`
+		TaxAmount     Amount      `xml:"cbc:TaxAmount"`
+		TaxCategory   TaxCategory `xml:"cac:TaxCategory"`
+	}
+
+	TaxCategory struct {
+		ID                     string    `xml:"cbc:ID"`
+		Percent                string    `xml:"cbc:Percent,omitempty"`
+		TaxExemptionReasonCode string    `xml:"cbc:TaxExemptionReasonCode,omitempty"`
+		TaxExemptionReason     string    `xml:"cbc:TaxExemptionReason,omitempty"`
+		TaxScheme              TaxScheme `xml:"cac:TaxScheme"`
+	}
+
+	MonetaryTotal struct {
+		LineExtensionAmount Amount `xml:"cbc:LineExtensionAmount"`
+		TaxExclusiveAmount  Amount `xml:"cbc:TaxExclusiveAmount"`
+		TaxInclusiveAmount  Amount `xml:"cbc:TaxInclusiveAmount"`
+		PayableAmount       Amount `xml:"cbc:PayableAmount"`
+	}
+
+	InvoiceLine struct {
+		ID                  string           `xml:"cbc:ID"`
+		InvoicedQuantity    Quantity         `xml:"cbc:InvoicedQuantity"`
+		LineExtensionAmount Amount           `xml:"cbc:LineExtensionAmount"`
+		InvoicePeriod       *Period          `xml:"cac:InvoicePeriod,omitempty"`
+		AllowanceCharge     *AllowanceCharge `xml:"cac:AllowanceCharge,omitempty"`
+		Item                Item             `xml:"cac:Item"`
+		Price               Price            `xml:"cac:Price"`
+	}
+
+	AllowanceCharge struct {
+		ChargeIndicator       bool   `xml:"cbc:ChargeIndicator"`
+		AllowanceChargeReason string `xml:"cbc:AllowanceChargeReason,omitempty"`
+		Amount                Amount `xml:"cbc:Amount"`
+	}
+
+	Item struct {
+		Description           string      `xml:"cbc:Description,omitempty"`
+		Name                  string      `xml:"cbc:Name"`
+		ClassifiedTaxCategory TaxCategory `xml:"cac:ClassifiedTaxCategory"`
+	}
+
+	Price struct {
+		PriceAmount Amount `xml:"cbc:PriceAmount"`
+	}
+)
@@ -2,14 +2,19 @@ module github.com/quail-ink/bizdocgen
 
 go 1.22.1
 
-require github.com/johnfercher/maroto/v2 v2.0.0-beta.17
+require (
+	github.com/johnfercher/maroto/v2 v2.0.0-beta.17
+	github.com/jung-kurt/gofpdf v1.16.2
+	github.com/srwiley/oksvg v0.0.0-20221011165216-be6e8873101c
+	github.com/srwiley/rasterx v0.0.0-20210519020934-456a8d69b780
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/boombuler/barcode v1.0.1 // indirect
 	github.com/f-amaral/go-async v0.3.0 // indirect
 	github.com/google/uuid v1.5.0 // indirect
-	github.com/jung-kurt/gofpdf v1.16.2 // indirect
-	golang.org/x/sys v0.5.0 // indirect
+	golang.org/x/net v0.20.0 // indirect
 )
 
 require (
@@ -19,12 +24,11 @@ require (
 	github.com/johnfercher/go-tree v1.0.5 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
 	github.com/nicksnyder/go-i18n/v2 v2.4.0
-	github.com/pdfcpu/pdfcpu v0.6.0 // indirect
+	github.com/pdfcpu/pdfcpu v0.6.0
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.4 // indirect
 	github.com/shopspring/decimal v1.3.1
-	github.com/sirupsen/logrus v1.9.3
-	golang.org/x/image v0.15.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0
+	golang.org/x/image v0.15.0
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
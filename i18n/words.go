@@ -0,0 +1,239 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/shopspring/decimal"
+)
+
+// Numerals are the numerals Japanese amounts are written in.
+type Numerals string
+
+const (
+	// NumeralsKanji writes amounts in common kanji numerals, 五十五万.
+	NumeralsKanji Numerals = "kanji"
+	// NumeralsDaiji writes amounts in the 大字 of receipts and formal
+	// invoices, 伍拾伍萬, which cannot be turned into larger amounts by
+	// adding strokes.
+	NumeralsDaiji Numerals = "daiji"
+)
+
+type (
+	// currencyWords are the names of the major and minor units of a
+	// currency, the minor ones empty for currencies without them.
+	currencyWords struct {
+		one, other           string
+		minorOne, minorOther string
+	}
+
+	// kanjiNumerals are the digits, powers of ten within groups of four
+	// digits, and group units of a numeral style.
+	kanjiNumerals struct {
+		digits [10]string
+		tens   [4]string
+		groups []string
+		// explicitOne writes 壱 before 拾, 百 and 阡.
+		explicitOne bool
+	}
+)
+
+var enCurrencyWords = map[string]currencyWords{
+	"AUD": {"Australian dollar", "Australian dollars", "cent", "cents"},
+	"CAD": {"Canadian dollar", "Canadian dollars", "cent", "cents"},
+	"CHF": {"Swiss franc", "Swiss francs", "centime", "centimes"},
+	"CNY": {"yuan", "yuan", "fen", "fen"},
+	"EUR": {"euro", "euros", "cent", "cents"},
+	"GBP": {"pound", "pounds", "penny", "pence"},
+	"HKD": {"Hong Kong dollar", "Hong Kong dollars", "cent", "cents"},
+	"INR": {"rupee", "rupees", "paisa", "paise"},
+	"JPY": {"yen", "yen", "", ""},
+	"NZD": {"New Zealand dollar", "New Zealand dollars", "cent", "cents"},
+	"SGD": {"Singapore dollar", "Singapore dollars", "cent", "cents"},
+	"TWD": {"New Taiwan dollar", "New Taiwan dollars", "cent", "cents"},
+	"USD": {"dollar", "dollars", "cent", "cents"},
+}
+
+var jaCurrencyWords = map[string]currencyWords{
+	"AUD": {"豪ドル", "豪ドル", "セント", "セント"},
+	"CAD": {"カナダドル", "カナダドル", "セント", "セント"},
+	"CHF": {"スイスフラン", "スイスフラン", "サンチーム", "サンチーム"},
+	"CNY": {"人民元", "人民元", "分", "分"},
+	"EUR": {"ユーロ", "ユーロ", "セント", "セント"},
+	"GBP": {"ポンド", "ポンド", "ペンス", "ペンス"},
+	"HKD": {"香港ドル", "香港ドル", "セント", "セント"},
+	"INR": {"ルピー", "ルピー", "パイサ", "パイサ"},
+	"JPY": {"円", "円", "", ""},
+	"NZD": {"NZドル", "NZドル", "セント", "セント"},
+	"SGD": {"シンガポールドル", "シンガポールドル", "セント", "セント"},
+	"TWD": {"台湾ドル", "台湾ドル", "セント", "セント"},
+	"USD": {"ドル", "ドル", "セント", "セント"},
+}
+
+var (
+	enOnes = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	enTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	enScales = []string{"", "thousand", "million", "billion", "trillion", "quadrillion"}
+
+	kanji = kanjiNumerals{
+		digits: [10]string{"〇", "一", "二", "三", "四", "五", "六", "七", "八", "九"},
+		tens:   [4]string{"", "十", "百", "千"},
+		groups: []string{"", "万", "億", "兆", "京"},
+	}
+	daiji = kanjiNumerals{
+		digits:      [10]string{"零", "壱", "弐", "参", "四", "伍", "六", "七", "八", "九"},
+		tens:        [4]string{"", "拾", "百", "阡"},
+		groups:      []string{"", "萬", "億", "兆", "京"},
+		explicitOne: true,
+	}
+)
+
+// maxWordsAmount bounds the amounts written in words, below 10^18 so that
+// major units fit in an int64.
+var maxWordsAmount = decimal.New(1, 18)
+
+// AmountInWords writes the amount in the currency out in words, rounded to
+// the minor units of the currency: "Five hundred fifty thousand yen" in
+// English and "金五十五万円也" in Japanese, in the given numerals. Other
+// languages are written in English.
+func AmountInWords(lang string, amount decimal.Decimal, currency string, numerals Numerals) (string, error) {
+	places := core.CurrencyPlaces(currency)
+	amount = amount.Round(places)
+	if amount.Abs().GreaterThanOrEqual(maxWordsAmount) {
+		return "", fmt.Errorf("amount %s is too large to be written in words", amount)
+	}
+	major := amount.Abs().IntPart()
+	minor := amount.Abs().Sub(decimal.NewFromInt(major)).Shift(places).IntPart()
+
+	if lang == "ja" {
+		return jaAmountInWords(amount.IsNegative(), major, minor, core.ISOCurrency(currency), numerals), nil
+	}
+	return enAmountInWords(amount.IsNegative(), major, minor, core.ISOCurrency(currency)), nil
+}
+
+func enAmountInWords(negative bool, major, minor int64, currency string) string {
+	names, known := enCurrencyWords[currency]
+	if !known {
+		names = currencyWords{one: currency, other: currency}
+	}
+	plural := func(n int64, one, other string) string {
+		if n == 1 {
+			return one
+		}
+		return other
+	}
+
+	s := enNumber(major) + " " + plural(major, names.one, names.other)
+	if minor != 0 {
+		if names.minorOne != "" {
+			s += " and " + enNumber(minor) + " " + plural(minor, names.minorOne, names.minorOther)
+		} else {
+			s += fmt.Sprintf(" and %02d/100", minor)
+		}
+	}
+	if negative {
+		s = "minus " + s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// enNumber writes the number out in English words on the short scale.
+func enNumber(n int64) string {
+	if n == 0 {
+		return enOnes[0]
+	}
+	words := []string{}
+	for scale := 0; n > 0; scale++ {
+		if group := n % 1000; group != 0 {
+			part := enHundreds(group)
+			if enScales[scale] != "" {
+				part += " " + enScales[scale]
+			}
+			words = append([]string{part}, words...)
+		}
+		n /= 1000
+	}
+	return strings.Join(words, " ")
+}
+
+// enHundreds writes a number below a thousand out in English words.
+func enHundreds(n int64) string {
+	words := []string{}
+	if n >= 100 {
+		words = append(words, enOnes[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		words = append(words, enTens[n/10]+"-"+enOnes[n%10])
+	case n >= 20:
+		words = append(words, enTens[n/10])
+	case n > 0:
+		words = append(words, enOnes[n])
+	}
+	return strings.Join(words, " ")
+}
+
+func jaAmountInWords(negative bool, major, minor int64, currency string, numerals Numerals) string {
+	style := kanji
+	if numerals == NumeralsDaiji {
+		style = daiji
+	}
+	names, known := jaCurrencyWords[currency]
+	if !known {
+		names = currencyWords{one: currency, other: currency}
+	}
+
+	s := "金"
+	if negative {
+		s += "マイナス"
+	}
+	s += style.number(major) + names.other
+	if minor != 0 {
+		if names.minorOther != "" {
+			s += style.number(minor) + names.minorOther
+		} else {
+			s += fmt.Sprintf("%02d/100", minor)
+		}
+	}
+	return s + "也"
+}
+
+// number writes the number out in the numerals, in groups of four digits.
+func (style kanjiNumerals) number(n int64) string {
+	if n == 0 {
+		return style.digits[0]
+	}
+	s := ""
+	for group := 0; n > 0; group++ {
+		if part := n % 10000; part != 0 {
+			s = style.thousands(part) + style.groups[group] + s
+		}
+		n /= 10000
+	}
+	return s
+}
+
+// thousands writes a number below ten thousand out in the numerals.
+func (style kanjiNumerals) thousands(n int64) string {
+	s := ""
+	for power := 3; power >= 0; power-- {
+		digit := n
+		for range power {
+			digit /= 10
+		}
+		digit %= 10
+		switch {
+		case digit == 0:
+		case digit == 1 && power > 0 && !style.explicitOne:
+			s += style.tens[power]
+		default:
+			s += style.digits[digit] + style.tens[power]
+		}
+	}
+	return s
+}
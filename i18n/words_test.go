@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAmountInWords(t *testing.T) {
+	for _, c := range []struct {
+		lang     string
+		amount   string
+		currency string
+		numerals Numerals
+		want     string
+	}{
+		{"en", "550000", "JPY", "", "Five hundred fifty thousand yen"},
+		{"en", "1234567.891", "USD", "", "One million two hundred thirty-four thousand five hundred sixty-seven dollars and eighty-nine cents"},
+		{"en", "1.01", "GBP", "", "One pound and one penny"},
+		{"en", "0", "EUR", "", "Zero euros"},
+		{"en", "-21", "USD", "", "Minus twenty-one dollars"},
+		{"en", "12.5", "SEK", "", "Twelve SEK and 50/100"},
+		{"ja", "550000", "JPY", NumeralsKanji, "金五十五万円也"},
+		{"ja", "100000", "JPY", NumeralsDaiji, "金壱拾萬円也"},
+		{"ja", "550000", "円", NumeralsDaiji, "金伍拾伍萬円也"},
+		{"ja", "1000010001", "JPY", "", "金十億一万一円也"},
+		{"ja", "1000010001", "JPY", NumeralsDaiji, "金壱拾億壱萬壱円也"},
+		{"ja", "12.5", "USD", "", "金十二ドル五十セント也"},
+	} {
+		got, err := AmountInWords(c.lang, decimal.RequireFromString(c.amount), c.currency, c.numerals)
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.amount, c.currency, err)
+		}
+		if got != c.want {
+			t.Errorf("%s %s %s: expected %q, got %q", c.lang, c.amount, c.currency, c.want, got)
+		}
+	}
+
+	if _, err := AmountInWords("en", decimal.New(1, 18), "USD", ""); err == nil {
+		t.Error("expected amounts of 10^18 to be too large")
+	}
+}
@@ -0,0 +1,110 @@
+package pdfa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// OutputCondition identifies the sRGB colour space declared as the output
+// intent of PDF/A documents.
+const OutputCondition = "sRGB IEC61966-2.1"
+
+// srgbProfile is a version 2 ICC display profile of sRGB, built once with
+// D50-adapted primaries and a sampled sRGB transfer curve.
+var srgbProfile = newSRGBProfile()
+
+type iccTag struct {
+	signature string
+	data      []byte
+}
+
+func newSRGBProfile() []byte {
+	trc := iccCurve(1024, func(v float64) float64 {
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	})
+	tags := []iccTag{
+		{"desc", iccDescription(OutputCondition)},
+		{"cprt", iccText("No copyright, use freely")},
+		{"wtpt", iccXYZ(0.9642, 1.0, 0.8249)},
+		{"rXYZ", iccXYZ(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", iccXYZ(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", iccXYZ(0.1431, 0.0606, 0.7141)},
+		{"rTRC", trc},
+		{"gTRC", trc},
+		{"bTRC", trc},
+	}
+
+	offset := 128 + 4 + 12*len(tags)
+	table := &bytes.Buffer{}
+	data := &bytes.Buffer{}
+	binary.Write(table, binary.BigEndian, uint32(len(tags)))
+	for _, tag := range tags {
+		table.WriteString(tag.signature)
+		binary.Write(table, binary.BigEndian, uint32(offset+data.Len()))
+		binary.Write(table, binary.BigEndian, uint32(len(tag.data)))
+		data.Write(tag.data)
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+	}
+
+	header := make([]byte, 128)
+	binary.BigEndian.PutUint32(header[0:], uint32(offset+data.Len()))
+	binary.BigEndian.PutUint32(header[8:], 0x02100000)
+	copy(header[12:], "mntr")
+	copy(header[16:], "RGB ")
+	copy(header[20:], "XYZ ")
+	for ix, v := range []uint16{2024, 1, 1, 0, 0, 0} {
+		binary.BigEndian.PutUint16(header[24+2*ix:], v)
+	}
+	copy(header[36:], "acsp")
+	copy(header[68:], iccXYZ(0.9642, 1.0, 0.8249)[8:])
+
+	profile := append(header, table.Bytes()...)
+	return append(profile, data.Bytes()...)
+}
+
+func iccS15Fixed16(v float64) uint32 {
+	return uint32(int32(math.Round(v * 65536)))
+}
+
+func iccXYZ(x, y, z float64) []byte {
+	buf := make([]byte, 20)
+	copy(buf, "XYZ ")
+	binary.BigEndian.PutUint32(buf[8:], iccS15Fixed16(x))
+	binary.BigEndian.PutUint32(buf[12:], iccS15Fixed16(y))
+	binary.BigEndian.PutUint32(buf[16:], iccS15Fixed16(z))
+	return buf
+}
+
+func iccCurve(n int, f func(float64) float64) []byte {
+	buf := make([]byte, 12+2*n)
+	copy(buf, "curv")
+	binary.BigEndian.PutUint32(buf[8:], uint32(n))
+	for ix := 0; ix < n; ix++ {
+		v := f(float64(ix) / float64(n-1))
+		binary.BigEndian.PutUint16(buf[12+2*ix:], uint16(math.Round(v*65535)))
+	}
+	return buf
+}
+
+func iccText(s string) []byte {
+	buf := append([]byte("text\x00\x00\x00\x00"), s...)
+	return append(buf, 0)
+}
+
+// iccDescription encodes a textDescriptionType with an empty Unicode and
+// ScriptCode description, as version 2 profiles require.
+func iccDescription(s string) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("desc\x00\x00\x00\x00")
+	binary.Write(buf, binary.BigEndian, uint32(len(s)+1))
+	buf.WriteString(s)
+	buf.WriteByte(0)
+	buf.Write(make([]byte, 4+4+2+1+67))
+	return buf.Bytes()
+}
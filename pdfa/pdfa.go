@@ -0,0 +1,281 @@
+// Package pdfa turns PDFs into PDF/A archival documents and checks existing
+// PDFs for the basics of PDF/A conformance.
+package pdfa
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Level is a PDF/A conformance level.
+type Level string
+
+const (
+	Level2B Level = "2b"
+	Level3B Level = "3b"
+)
+
+// Levels lists every supported conformance level.
+var Levels = []Level{Level2B, Level3B}
+
+// Part returns the part of ISO 19005 the level belongs to, or 0 for unknown
+// levels.
+func (l Level) Part() int {
+	switch l {
+	case Level2B:
+		return 2
+	case Level3B:
+		return 3
+	}
+	return 0
+}
+
+// Metadata describes the document in its information dictionary and XMP
+// metadata.
+type Metadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Creator  string
+	// DocumentID identifies the document across renditions, such as a UUID
+	// URN derived from its number.
+	DocumentID string
+	// Extension holds additional rdf:Description elements of the XMP
+	// packet, such as the properties of an embedded e-invoice together with
+	// their extension schema.
+	Extension string
+}
+
+// Placeholders of the XMP dates, replaced once the writer has stamped the
+// information dictionary. Both have the length of dateLayout.
+const (
+	createDatePlaceholder = "YYYY-MM-DDThh:mm:ss+hh:mm"
+	modifyDatePlaceholder = "yyyy-mm-ddThh:mm:ss+hh:mm"
+	dateLayout            = "2006-01-02T15:04:05-07:00"
+)
+
+var xmpTemplate = template.Must(template.New("xmp").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(`<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+  <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+    <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+      <pdfaid:part>{{.Part}}</pdfaid:part>
+      <pdfaid:conformance>B</pdfaid:conformance>
+    </rdf:Description>
+    <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+      <dc:format>application/pdf</dc:format>
+{{- with .Title}}
+      <dc:title><rdf:Alt><rdf:li xml:lang="x-default">{{xml .}}</rdf:li></rdf:Alt></dc:title>
+{{- end}}
+{{- with .Author}}
+      <dc:creator><rdf:Seq><rdf:li>{{xml .}}</rdf:li></rdf:Seq></dc:creator>
+{{- end}}
+{{- with .Subject}}
+      <dc:description><rdf:Alt><rdf:li xml:lang="x-default">{{xml .}}</rdf:li></rdf:Alt></dc:description>
+{{- end}}
+    </rdf:Description>
+    <rdf:Description rdf:about="" xmlns:pdf="http://ns.adobe.com/pdf/1.3/">
+      <pdf:Producer>{{xml .Producer}}</pdf:Producer>
+{{- with .Keywords}}
+      <pdf:Keywords>{{xml .}}</pdf:Keywords>
+{{- end}}
+    </rdf:Description>
+    <rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/">
+{{- with .Creator}}
+      <xmp:CreatorTool>{{xml .}}</xmp:CreatorTool>
+{{- end}}
+      <xmp:CreateDate>{{.CreateDate}}</xmp:CreateDate>
+      <xmp:ModifyDate>{{.ModifyDate}}</xmp:ModifyDate>
+      <xmp:MetadataDate>{{.ModifyDate}}</xmp:MetadataDate>
+    </rdf:Description>
+{{- with .DocumentID}}
+    <rdf:Description rdf:about="" xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/">
+      <xmpMM:DocumentID>{{xml .}}</xmpMM:DocumentID>
+    </rdf:Description>
+{{- end}}
+{{.Extension}}
+  </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>
+`))
+
+func xmlEscape(s string) string {
+	buf := &bytes.Buffer{}
+	_ = xml.EscapeText(buf, []byte(s))
+	return buf.String()
+}
+
+// producer is the Producer the pdfcpu writer stamps on every document it
+// writes, which the XMP metadata has to repeat.
+func producer() string {
+	return "pdfcpu " + model.VersionStr
+}
+
+// Apply adds the sRGB output intent, the document information and the XMP
+// metadata of the level to the document. Write the context with Write so
+// that the XMP dates match the information dictionary. Fonts are not
+// touched: documents only conform when every font is embedded.
+func Apply(ctx *model.Context, level Level, m Metadata) error {
+	if level.Part() == 0 {
+		return fmt.Errorf("unknown PDF/A level %q", level)
+	}
+	xRefTable := ctx.XRefTable
+	root, err := xRefTable.Catalog()
+	if err != nil {
+		return err
+	}
+
+	profile, err := xRefTable.NewStreamDictForBuf(srgbProfile)
+	if err != nil {
+		return err
+	}
+	profile.InsertInt("N", 3)
+	if err := profile.Encode(); err != nil {
+		return err
+	}
+	profileRef, err := xRefTable.IndRefForNewObject(*profile)
+	if err != nil {
+		return err
+	}
+	intent := types.Dict(map[string]types.Object{
+		"Type":                      types.Name("OutputIntent"),
+		"S":                         types.Name("GTS_PDFA1"),
+		"OutputConditionIdentifier": types.StringLiteral(OutputCondition),
+		"Info":                      types.StringLiteral(OutputCondition),
+		"RegistryName":              types.StringLiteral("http://www.color.org"),
+		"DestOutputProfile":         *profileRef,
+	})
+	root.Update("OutputIntents", types.Array{intent})
+
+	if err := SetInfo(ctx, m); err != nil {
+		return err
+	}
+
+	xmp := &bytes.Buffer{}
+	if err := xmpTemplate.Execute(xmp, struct {
+		Metadata
+		Part       int
+		Producer   string
+		CreateDate string
+		ModifyDate string
+	}{m, level.Part(), producer(), createDatePlaceholder, modifyDatePlaceholder}); err != nil {
+		return err
+	}
+	metadata := types.NewStreamDict(types.NewDict(), 0, nil, nil, nil)
+	metadata.Content = xmp.Bytes()
+	metadata.InsertName("Type", "Metadata")
+	metadata.InsertName("Subtype", "XML")
+	if err := metadata.Encode(); err != nil {
+		return err
+	}
+	metadataRef, err := xRefTable.IndRefForNewObject(metadata)
+	if err != nil {
+		return err
+	}
+	root.Update("Metadata", *metadataRef)
+	return nil
+}
+
+// SetInfo sets the title, author, subject, keywords and creator of the
+// document information dictionary, removing the entries left empty.
+func SetInfo(ctx *model.Context, m Metadata) error {
+	if ctx.Info == nil {
+		ref, err := ctx.IndRefForNewObject(types.NewDict())
+		if err != nil {
+			return err
+		}
+		ctx.Info = ref
+	}
+	info, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil {
+		return err
+	}
+	for key, value := range map[string]string{
+		"Title":    m.Title,
+		"Author":   m.Author,
+		"Subject":  m.Subject,
+		"Keywords": m.Keywords,
+		"Creator":  m.Creator,
+	} {
+		if value == "" {
+			info.Delete(key)
+		} else {
+			info.Update(key, textString(value))
+		}
+	}
+	return nil
+}
+
+// textString encodes s as a PDF text string, in UTF-16 when it is not plain
+// ASCII.
+func textString(s string) types.Object {
+	for _, r := range s {
+		if r > 0x7e || r < 0x20 || r == '(' || r == ')' || r == '\\' {
+			return types.NewHexLiteral([]byte(types.EncodeUTF16String(s)))
+		}
+	}
+	return types.StringLiteral(s)
+}
+
+// Write writes the context and fills in the XMP dates with the creation and
+// modification dates the writer stamped on the information dictionary. The
+// dates replace placeholders of the same length in the uncompressed
+// metadata stream, so no offsets change.
+func Write(ctx *model.Context) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := api.WriteContext(ctx, buf); err != nil {
+		return nil, err
+	}
+	pdf := buf.Bytes()
+
+	written, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, err
+	}
+	created, modified, err := infoDates(written)
+	if err != nil {
+		return nil, err
+	}
+	pdf = bytes.Replace(pdf, []byte(createDatePlaceholder), []byte(created.Format(dateLayout)), -1)
+	pdf = bytes.Replace(pdf, []byte(modifyDatePlaceholder), []byte(modified.Format(dateLayout)), -1)
+	return pdf, nil
+}
+
+func infoDates(ctx *model.Context) (created, modified time.Time, err error) {
+	if ctx.Info == nil {
+		return created, modified, fmt.Errorf("document has no information dictionary")
+	}
+	info, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil {
+		return created, modified, err
+	}
+	created, err = infoDate(ctx, info, "CreationDate")
+	if err != nil {
+		return created, modified, err
+	}
+	modified, err = infoDate(ctx, info, "ModDate")
+	return created, modified, err
+}
+
+func infoDate(ctx *model.Context, info types.Dict, key string) (time.Time, error) {
+	o, ok := info.Find(key)
+	if !ok {
+		return time.Time{}, fmt.Errorf("document information has no %s", key)
+	}
+	s, err := ctx.DereferenceText(o)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, ok := types.DateTime(s, true)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid %s %q", key, s)
+	}
+	return t, nil
+}
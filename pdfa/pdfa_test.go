@@ -0,0 +1,78 @@
+package pdfa
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func samplePDF(t *testing.T) []byte {
+	m := maroto.New()
+	m.AddRow(10, text.NewCol(12, "archive"))
+	doc, err := m.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.GetBytes()
+}
+
+func TestApply(t *testing.T) {
+	pdf := samplePDF(t)
+
+	report, err := Verify(bytes.NewReader(pdf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.OK() || report.Part != 0 {
+		t.Fatalf("expected a plain PDF not to conform, got %+v", report)
+	}
+
+	ctx, err := api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(ctx, Level2B, Metadata{Title: "Invoice 1 – 春日町 & Co", Author: "春日町", DocumentID: "uuid:1"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Write(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = Verify(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Part != 2 || report.Conformance != "B" {
+		t.Fatalf("unexpected conformance %d%s", report.Part, report.Conformance)
+	}
+	// maroto falls back to the standard Helvetica fonts, which are never
+	// embedded.
+	for _, problem := range report.Problems {
+		if !strings.HasPrefix(problem, "font Helvetica") {
+			t.Fatalf("unexpected problem %s", problem)
+		}
+	}
+	if !bytes.Contains(out, []byte("Invoice 1 – 春日町 &amp; Co")) {
+		t.Fatal("expected the title in the XMP metadata")
+	}
+
+	ctx, _ = api.ReadContext(bytes.NewReader(pdf), model.NewDefaultConfiguration())
+	if err := Apply(ctx, "1a", Metadata{}); err == nil {
+		t.Fatal("expected unknown levels to be refused")
+	}
+}
+
+func TestSRGBProfile(t *testing.T) {
+	if got := len(srgbProfile); got%4 != 0 || got != int(srgbProfile[0])<<24|int(srgbProfile[1])<<16|int(srgbProfile[2])<<8|int(srgbProfile[3]) {
+		t.Fatalf("profile size %d does not match its header", got)
+	}
+	if string(srgbProfile[36:40]) != "acsp" || string(srgbProfile[16:20]) != "RGB " {
+		t.Fatal("invalid profile header")
+	}
+}
@@ -0,0 +1,290 @@
+package pdfa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Report lists the PDF/A problems found in a document. It covers the basics
+// an archive relies on, not every rule of ISO 19005; use a full validator
+// such as veraPDF for certification.
+type Report struct {
+	// Part and Conformance are declared by the XMP metadata, 0 and "" when
+	// the document declares none.
+	Part        int
+	Conformance string
+	Problems    []string
+}
+
+func (r *Report) addProblem(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// OK reports whether no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Err returns the problems as a single error, or nil when there are none.
+func (r *Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	return errors.New("not PDF/A: " + strings.Join(r.Problems, "; "))
+}
+
+var (
+	xmpPart        = regexp.MustCompile(`pdfaid:part(?:>\s*|\s*=\s*["'])(\d)`)
+	xmpConformance = regexp.MustCompile(`pdfaid:conformance(?:>\s*|\s*=\s*["'])([ABUabu])`)
+	xmpTitle       = regexp.MustCompile(`(?s)<dc:title>.*?<rdf:li[^>]*>(.*?)</rdf:li>`)
+	xmpProducer    = regexp.MustCompile(`(?s)<pdf:Producer>(.*?)</pdf:Producer>`)
+	xmpCreateDate  = regexp.MustCompile(`(?s)<xmp:CreateDate>(.*?)</xmp:CreateDate>`)
+)
+
+// Verify checks a PDF for the basics of PDF/A conformance: the file header,
+// no encryption, a file identifier, XMP metadata declaring the part and
+// conformance and agreeing with the document information, an output intent,
+// embedded fonts, no JavaScript, and attachments the declared part allows.
+// It returns an error only when the PDF cannot be read.
+func Verify(rs io.ReadSeeker) (*Report, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(rs, header); err != nil {
+		return nil, err
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	ctx, err := api.ReadContext(rs, model.NewDefaultConfiguration())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{}
+	checkHeader(r, header)
+	if ctx.Encrypt != nil {
+		r.addProblem("document is encrypted")
+	}
+	if len(ctx.ID) != 2 {
+		r.addProblem("trailer has no file identifier")
+	}
+	root, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	checkMetadata(r, ctx, root)
+	checkOutputIntents(r, ctx, root)
+	checkObjects(r, ctx)
+	return r, nil
+}
+
+func checkHeader(r *Report, header []byte) {
+	if !bytes.HasPrefix(header, []byte("%PDF-1.")) || header[7] < '0' || header[7] > '7' {
+		r.addProblem("header is not %%PDF-1.0 to %%PDF-1.7")
+		return
+	}
+	lines := bytes.SplitN(header, []byte("\n"), 3)
+	if len(lines) < 2 || len(lines[1]) < 5 || lines[1][0] != '%' {
+		r.addProblem("header is not followed by a binary comment")
+		return
+	}
+	for _, c := range lines[1][1:5] {
+		if c < 128 {
+			r.addProblem("header is not followed by a binary comment")
+			return
+		}
+	}
+}
+
+func checkMetadata(r *Report, ctx *model.Context, root types.Dict) {
+	o, ok := root.Find("Metadata")
+	if !ok {
+		r.addProblem("catalog has no XMP metadata")
+		return
+	}
+	sd, _, err := ctx.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		r.addProblem("XMP metadata is not a stream")
+		return
+	}
+	if len(sd.FilterPipeline) > 0 {
+		r.addProblem("XMP metadata stream is compressed")
+	}
+	if err := sd.Decode(); err != nil {
+		r.addProblem("XMP metadata cannot be decoded")
+		return
+	}
+	xmp := sd.Content
+
+	if m := xmpPart.FindSubmatch(xmp); m != nil {
+		r.Part, _ = strconv.Atoi(string(m[1]))
+	} else {
+		r.addProblem("XMP metadata declares no pdfaid:part")
+	}
+	if m := xmpConformance.FindSubmatch(xmp); m != nil {
+		r.Conformance = strings.ToUpper(string(m[1]))
+	} else {
+		r.addProblem("XMP metadata declares no pdfaid:conformance")
+	}
+
+	if ctx.Info == nil {
+		return
+	}
+	info, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || info == nil {
+		return
+	}
+	if title, ok := infoText(ctx, info, "Title"); ok && title != xmpValue(xmpTitle, xmp) {
+		r.addProblem("document title %q does not match dc:title", title)
+	}
+	if producer, ok := infoText(ctx, info, "Producer"); ok && producer != xmpValue(xmpProducer, xmp) {
+		r.addProblem("document producer %q does not match pdf:Producer", producer)
+	}
+	if created, ok := infoText(ctx, info, "CreationDate"); ok {
+		t, ok := types.DateTime(created, true)
+		x, err := time.Parse(time.RFC3339, xmpValue(xmpCreateDate, xmp))
+		if !ok || err != nil || !t.Equal(x) {
+			r.addProblem("document creation date %q does not match xmp:CreateDate", created)
+		}
+	}
+}
+
+func infoText(ctx *model.Context, info types.Dict, key string) (string, bool) {
+	o, ok := info.Find(key)
+	if !ok {
+		return "", false
+	}
+	s, err := ctx.DereferenceText(o)
+	return s, err == nil
+}
+
+func xmpValue(re *regexp.Regexp, xmp []byte) string {
+	m := re.FindSubmatch(xmp)
+	if m == nil {
+		return ""
+	}
+	return html.UnescapeString(strings.TrimSpace(string(m[1])))
+}
+
+func checkOutputIntents(r *Report, ctx *model.Context, root types.Dict) {
+	o, ok := root.Find("OutputIntents")
+	if !ok {
+		r.addProblem("catalog has no output intent")
+		return
+	}
+	intents, err := ctx.DereferenceArray(o)
+	if err != nil {
+		r.addProblem("output intents are not an array")
+		return
+	}
+	for _, o := range intents {
+		intent, err := ctx.DereferenceDict(o)
+		if err != nil || intent == nil {
+			continue
+		}
+		if s := intent.NameEntry("S"); s != nil && *s == "GTS_PDFA1" {
+			if _, ok := intent.Find("DestOutputProfile"); !ok {
+				r.addProblem("PDF/A output intent has no DestOutputProfile")
+			}
+			return
+		}
+	}
+	r.addProblem("catalog has no GTS_PDFA1 output intent")
+}
+
+// checkObjects looks for fonts that are not embedded, JavaScript actions and
+// attachments.
+func checkObjects(r *Report, ctx *model.Context) {
+	fonts := map[string]bool{}
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free {
+			continue
+		}
+		var d types.Dict
+		switch o := entry.Object.(type) {
+		case types.Dict:
+			d = o
+		case types.StreamDict:
+			d = o.Dict
+		default:
+			continue
+		}
+		switch {
+		case d.Type() != nil && *d.Type() == "Font":
+			if name, ok := fontNotEmbedded(ctx, d); ok {
+				fonts[name] = true
+			}
+		case d.NameEntry("S") != nil && *d.NameEntry("S") == "JavaScript":
+			r.addProblem("document contains JavaScript")
+		case d.Type() != nil && *d.Type() == "Filespec":
+			checkFileSpec(r, ctx, d)
+		}
+	}
+
+	names := make([]string, 0, len(fonts))
+	for name := range fonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.addProblem("font %s is not embedded", name)
+	}
+}
+
+func fontNotEmbedded(ctx *model.Context, font types.Dict) (string, bool) {
+	subtype := font.Subtype()
+	if subtype != nil && (*subtype == "Type0" || *subtype == "Type3") {
+		// Type0 fonts are embedded through their descendant font, Type3
+		// glyphs are content streams.
+		return "", false
+	}
+	name := "unnamed"
+	if base := font.NameEntry("BaseFont"); base != nil {
+		name = *base
+	}
+	o, ok := font.Find("FontDescriptor")
+	if !ok {
+		return name, true
+	}
+	descriptor, err := ctx.DereferenceDict(o)
+	if err != nil || descriptor == nil {
+		return name, true
+	}
+	for _, key := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		if _, ok := descriptor.Find(key); ok {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+func checkFileSpec(r *Report, ctx *model.Context, spec types.Dict) {
+	if _, ok := spec.Find("EF"); !ok {
+		return
+	}
+	name, _ := infoText(ctx, spec, "UF")
+	if name == "" {
+		name, _ = infoText(ctx, spec, "F")
+	}
+	switch r.Part {
+	case 2:
+		if !strings.HasSuffix(strings.ToLower(name), ".pdf") {
+			r.addProblem("embedded file %s is not allowed in PDF/A-2", name)
+		}
+	case 3:
+		if spec.NameEntry("AFRelationship") == nil {
+			r.addProblem("embedded file %s has no AFRelationship", name)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package server exposes the document builders over HTTP.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/quail-ink/bizdocgen/builder"
+	"github.com/quail-ink/bizdocgen/core"
+	"github.com/quail-ink/bizdocgen/einvoice"
+)
+
+// DefaultProfile is the font profile used when a request does not select one.
+const DefaultProfile = "default"
+
+// DefaultMaxBodyBytes limits the size of params accepted in a request.
+const DefaultMaxBodyBytes = 1 << 20
+
+type (
+	Options struct {
+		// Profiles maps profile names to builder configs. Fonts should be
+		// preloaded into Config.CustomFonts with builder.LoadFonts.
+		Profiles map[string]builder.Config
+		// Seals maps CompanySeal and CompanyLogo values of params to
+		// preloaded images. Image files are never read from the filesystem
+		// by the server.
+		Seals        map[string][]byte
+		MaxBodyBytes int64
+	}
+
+	Server struct {
+		opts Options
+		mux  *http.ServeMux
+	}
+
+	errorResponse struct {
+		Error    string             `json:"error"`
+		Errors   []*core.FieldError `json:"errors,omitempty"`
+		Warnings []*core.FieldError `json:"warnings,omitempty"`
+	}
+)
+
+// New creates the HTTP handler serving one POST endpoint per document type,
+// e.g. POST /v1/invoice, the JSON Schema of its params at
+// GET /v1/invoice/schema, and GET /healthz.
+func New(opts Options) *Server {
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if opts.Profiles == nil {
+		opts.Profiles = map[string]builder.Config{}
+	}
+	if _, ok := opts.Profiles[DefaultProfile]; !ok {
+		opts.Profiles[DefaultProfile] = builder.Config{}
+	}
+	if opts.Seals == nil {
+		opts.Seals = map[string][]byte{}
+	}
+
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	for _, docType := range builder.DocumentTypes {
+		s.mux.HandleFunc(fmt.Sprintf("POST /v1/%s", docType), s.handleGenerate(docType))
+		s.mux.HandleFunc(fmt.Sprintf("GET /v1/%s/schema", docType), s.handleSchema(docType))
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+func (s *Server) handleSchema(docType builder.DocumentType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		schema, err := builder.JSONSchema(docType)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, &errorResponse{Error: err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		json.NewEncoder(w).Encode(schema)
+	}
+}
+
+// handleGenerate renders the document posted as JSON or YAML params. The
+// "profile" and "lang" query parameters select the font profile and
+// language, "facturx" embeds Factur-X XML of the given profile into
+// invoices, and "format=html" returns HTML instead of PDF.
+func (s *Server) handleGenerate(docType builder.DocumentType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profile := r.URL.Query().Get("profile")
+		if profile == "" {
+			profile = DefaultProfile
+		}
+		cfg, ok := s.opts.Profiles[profile]
+		if !ok {
+			writeError(w, http.StatusBadRequest, &errorResponse{Error: fmt.Sprintf("unknown profile %q", profile)})
+			return
+		}
+		if lang := r.URL.Query().Get("lang"); lang != "" {
+			cfg.Lang = lang
+		}
+		if facturX := r.URL.Query().Get("facturx"); facturX != "" && docType == builder.DocumentInvoice {
+			cfg.FacturX = einvoice.CIIProfile(facturX)
+		}
+		cfg.Seals = s.opts.Seals
+
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes))
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeError(w, http.StatusRequestEntityTooLarge, &errorResponse{Error: fmt.Sprintf("params exceed %d bytes", maxErr.Limit)})
+				return
+			}
+			writeError(w, http.StatusBadRequest, &errorResponse{Error: err.Error()})
+			return
+		}
+
+		b, err := builder.NewBuilderFromBytes(docType, cfg, data)
+		if err != nil {
+			resp := &errorResponse{Error: "invalid params"}
+			var fieldErrs core.FieldErrors
+			if errors.As(err, &fieldErrs) {
+				resp.Errors = fieldErrs
+			} else {
+				resp.Error = err.Error()
+			}
+			writeError(w, http.StatusBadRequest, resp)
+			return
+		}
+
+		report := b.Validate()
+		if !report.OK() {
+			writeError(w, http.StatusUnprocessableEntity, &errorResponse{
+				Error:    "params failed validation",
+				Errors:   report.Errors,
+				Warnings: report.Warnings,
+			})
+			return
+		}
+
+		generate, contentType, ext := b.Generate, "application/pdf", "pdf"
+		if r.URL.Query().Get("format") == "html" {
+			generate, contentType, ext = b.GenerateHTML, "text/html; charset=utf-8", "html"
+		}
+		buf, err := generate()
+		if err != nil {
+			slog.Error("failed to render document", "type", docType, "error", err)
+			writeError(w, http.StatusInternalServerError, &errorResponse{Error: err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": b.SuggestedFilename(ext)}))
+		w.Write(buf)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, resp *errorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+	seal, err := os.ReadFile("../sample-seal.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(Options{
+		Seals: map[string][]byte{"../sample-seal.png": seal},
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	s := newTestServer(t)
+
+	for path, filename := range map[string]string{
+		"/v1/invoice":   "../sample-params/invoice-2.yaml",
+		"/v1/statement": "../sample-params/paymentstatement-1.yaml",
+	} {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPost, path+"?lang=ja", bytes.NewReader(data))
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", path, rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/pdf" {
+			t.Fatalf("%s: content type %q", path, ct)
+		}
+		if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")) {
+			t.Fatalf("%s: body is not a PDF", path)
+		}
+		if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "inline; filename=") || !strings.Contains(cd, ".pdf") {
+			t.Fatalf("%s: content disposition %q", path, cd)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	s := New(Options{MaxBodyBytes: 1024})
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+	}{
+		{"syntax", "/v1/invoice", "id: [", http.StatusBadRequest},
+		{"profile", "/v1/invoice?profile=missing", `{"id": "1"}`, http.StatusBadRequest},
+		{"validation", "/v1/invoice", `{"id": "INV-1", "currency": "USD"}`, http.StatusUnprocessableEntity},
+		{"size", "/v1/invoice", "id: " + strings.Repeat("x", 2048), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d: %s", tt.name, tt.status, rec.Code, rec.Body.String())
+		}
+		resp := &errorResponse{}
+		if err := json.NewDecoder(rec.Body).Decode(resp); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.status == http.StatusUnprocessableEntity && len(resp.Errors) == 0 {
+			t.Fatalf("%s: expected field errors", tt.name)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+}